@@ -0,0 +1,110 @@
+package main
+
+import "time"
+
+// Achievement is a goal that a profile unlocks once
+type Achievement struct {
+	ID          string
+	Name        string
+	Description string
+}
+
+var achievements = []Achievement{
+	{"first-bite", "First Bite", "Eat a ghost"},
+	{"fruit", "Five a Day", "Collect a bonus fruit"},
+	{"flawless", "Flawless", "Clear a level without dying"},
+	{"ghostbuster", "Ghostbuster", "Eat four ghosts on one pill"},
+	{"maze01-speedrun", "Speedrunner", "Clear maze01 in under 90 seconds"},
+}
+
+func findAchievement(id string) (Achievement, bool) {
+	for _, a := range achievements {
+		if a.ID == id {
+			return a, true
+		}
+	}
+	return Achievement{}, false
+}
+
+// Achievements unlocks achievements from the events published by the games
+// it watches
+type Achievements struct {
+	unlocked map[string]time.Time
+
+	// OnUnlock, if set, is called for every new achievement
+	OnUnlock func(a Achievement)
+
+	// state of the game being watched
+	speedrun    bool // whether the game is on the speedrun maze
+	frameRate   int  // ticks per second of game time
+	deaths      int
+	ghostsEaten int
+}
+
+// NewAchievements starts from the achievements a profile has already
+// unlocked, so they are not announced again
+func NewAchievements(unlocked map[string]time.Time) *Achievements {
+	a := &Achievements{unlocked: make(map[string]time.Time)}
+	for id, at := range unlocked {
+		a.unlocked[id] = at
+	}
+	return a
+}
+
+// Watch subscribes to the events of a new game on maze
+func (a *Achievements) Watch(g *Game, maze Maze) {
+	a.speedrun = isSpeedrunMaze(maze)
+	a.frameRate = g.rules.FrameRate
+	a.deaths = 0
+	a.ghostsEaten = 0
+	g.events.Subscribe(a.handle)
+}
+
+// Unlocked returns when each unlocked achievement was unlocked, by ID
+func (a *Achievements) Unlocked() map[string]time.Time {
+	return a.unlocked
+}
+
+func (a *Achievements) handle(e Event) {
+	switch e.Type {
+	case EventPillEaten:
+		a.ghostsEaten = 0
+	case EventGhostEaten:
+		a.ghostsEaten++
+		a.unlock("first-bite")
+		if a.ghostsEaten == 4 {
+			a.unlock("ghostbuster")
+		}
+	case EventFruitCollected:
+		a.unlock("fruit")
+	case EventDeath:
+		a.deaths++
+	case EventLevelClear:
+		if a.deaths == 0 {
+			a.unlock("flawless")
+		}
+		elapsed := time.Duration(e.Tick) * time.Second / time.Duration(a.frameRate)
+		if a.speedrun && elapsed < 90*time.Second {
+			a.unlock("maze01-speedrun")
+		}
+	}
+}
+
+// isSpeedrunMaze reports whether maze is the built-in maze01, recognised by
+// its contents so that a copy under another name counts but an edited maze
+// called maze01.txt doesn't
+func isSpeedrunMaze(maze Maze) bool {
+	builtin, err := loadMaze("")
+	return err == nil && mazeID(maze) == mazeID(builtin)
+}
+
+func (a *Achievements) unlock(id string) {
+	if _, ok := a.unlocked[id]; ok {
+		return
+	}
+	a.unlocked[id] = time.Now()
+
+	if achievement, ok := findAchievement(id); ok && a.OnUnlock != nil {
+		a.OnUnlock(achievement)
+	}
+}
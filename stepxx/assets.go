@@ -0,0 +1,106 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// assets are the default maze and configurations, built into the binary so
+// it runs from any directory
+//
+//go:embed maze01.txt config.json config_noemoji.json
+var assets embed.FS
+
+// Assets used when no file is given
+const (
+	defaultMazeFile   = "maze01.txt"
+	defaultConfigFile = "config.json"
+)
+
+// openAsset opens the file at path, or the built-in asset called name if
+// path is empty. A path naming a built-in asset, like config_noemoji.json,
+// falls back to the asset when there is no such file on disk.
+func openAsset(path, name string) (io.ReadCloser, error) {
+	if path == "" {
+		return assets.Open(name)
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		if asset, assetErr := assets.Open(path); assetErr == nil {
+			return asset, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// mazeName is the name of the maze being played, as shown to the player
+// and used in the statistics
+func mazeName() string {
+	if *mazeFile == "" {
+		return defaultMazeFile
+	}
+	return filepath.Base(*mazeFile)
+}
+
+// runAssets is the assets command. "assets export <dir>" writes the
+// built-in assets to dir, to use as a starting point for custom ones.
+func runAssets(args []string) error {
+	flags := flag.NewFlagSet("assets", flag.ExitOnError)
+	force := flags.Bool("force", false, "overwrite files that already exist")
+	if len(args) == 0 || args[0] != "export" {
+		return errors.New("usage: assets export [--force] <dir>")
+	}
+	flags.Parse(args[1:])
+	if flags.NArg() != 1 {
+		return errors.New("usage: assets export [--force] <dir>")
+	}
+	dir := flags.Arg(0)
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	entries, err := assets.ReadDir(".")
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		data, err := assets.ReadFile(entry.Name())
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		mode := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if !*force {
+			mode |= os.O_EXCL
+		}
+		f, err := os.OpenFile(path, mode, 0644)
+		if errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("%s already exists, use --force to overwrite it", path)
+		}
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println("Wrote", path)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import "math/rand"
+
+func init() {
+	RegisterController("greedy", func(int64) Controller { return greedy{} })
+	RegisterController("random", func(seed int64) Controller {
+		return &randomWalk{rng: rand.New(rand.NewSource(seed))}
+	})
+}
+
+// greedyDanger is how many steps away from a ghost the greedy bot considers
+// unsafe
+const greedyDanger = 2
+
+// greedy heads for the nearest dot or pill along the shortest path that
+// keeps clear of ghosts, and runs away when there is no such path
+type greedy struct{}
+
+func (greedy) Direction(v View) string {
+	danger := dangerZone(v)
+	if dir := nearestFood(v, danger); dir != "" {
+		return dir
+	}
+	return flee(v)
+}
+
+// dangerZone returns every tile a ghost that isn't blue can reach within
+// greedyDanger steps
+func dangerZone(v View) map[Point]bool {
+	danger := make(map[Point]bool)
+	for _, ghost := range v.Ghosts() {
+		if ghost.Blue {
+			continue
+		}
+
+		frontier := []Point{ghost.Position}
+		danger[ghost.Position] = true
+		for i := 0; i < greedyDanger; i++ {
+			var next []Point
+			for _, p := range frontier {
+				for _, dir := range directions {
+					if n, ok := v.Move(p, dir); ok && !danger[n] {
+						danger[n] = true
+						next = append(next, n)
+					}
+				}
+			}
+			frontier = next
+		}
+	}
+	return danger
+}
+
+// nearestFood runs a breadth first search from the player avoiding danger
+// and returns the first step towards the closest dot or pill
+func nearestFood(v View, danger map[Point]bool) string {
+	start := v.Player()
+	first := map[Point]string{start: ""}
+	queue := []Point{start}
+
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+
+		if tile := v.Tile(p); p != start && (tile == '.' || tile == 'X') {
+			return first[p]
+		}
+
+		for _, dir := range directions {
+			n, ok := v.Move(p, dir)
+			if !ok || danger[n] {
+				continue
+			}
+			if _, seen := first[n]; seen {
+				continue
+			}
+			if p == start {
+				first[n] = dir
+			} else {
+				first[n] = first[p]
+			}
+			queue = append(queue, n)
+		}
+	}
+	return ""
+}
+
+// flee returns the move that takes the player furthest from the closest
+// dangerous ghost
+func flee(v View) string {
+	best, bestDist := "", -1
+	for _, dir := range directions {
+		n, ok := v.Move(v.Player(), dir)
+		if !ok {
+			continue
+		}
+
+		dist := -1
+		for _, ghost := range v.Ghosts() {
+			if ghost.Blue {
+				continue
+			}
+			if d := distance(n, ghost.Position); dist < 0 || d < dist {
+				dist = d
+			}
+		}
+		if dist > bestDist {
+			best, bestDist = dir, dist
+		}
+	}
+	return best
+}
+
+// randomWalk wanders around, changing direction when it hits a wall or on
+// a whim. It is a baseline to compare smarter bots against.
+type randomWalk struct {
+	rng *rand.Rand
+	dir string
+}
+
+func (r *randomWalk) Direction(v View) string {
+	if _, ok := v.Move(v.Player(), r.dir); !ok || r.rng.Intn(8) == 0 {
+		r.dir = directions[r.rng.Intn(len(directions))]
+	}
+	return r.dir
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// output is where flushFrame writes the frames for the player, normally
+// the terminal
+var output io.Writer = os.Stdout
+
+// CastRecorder passes everything written to it through to another writer,
+// and records it with timestamps in asciicast v2 format
+// (https://docs.asciinema.org/manual/asciicast/v2/), so the session can be
+// played back with asciinema and other standard players.
+type CastRecorder struct {
+	w     io.Writer
+	f     *os.File
+	start time.Time
+	rows  int
+	cols  int
+	err   error
+}
+
+// castHeader is the first line of an asciicast file
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// NewCastRecorder creates the file at path and records everything written
+// to w into it
+func NewCastRecorder(path string, w io.Writer) (*CastRecorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &CastRecorder{w: w, f: f, start: time.Now()}
+	r.rows, r.cols = castSize()
+
+	header, err := json.Marshal(castHeader{
+		Version:   2,
+		Width:     r.cols,
+		Height:    r.rows,
+		Timestamp: r.start.Unix(),
+		Title:     "pacgo " + mazeName(),
+		Env:       map[string]string{"TERM": os.Getenv("TERM"), "SHELL": os.Getenv("SHELL")},
+	})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	_, err = f.Write(append(header, '\n'))
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// castSize returns the terminal size, or the usual 80x24 while it is
+// unknown
+func castSize() (rows, cols int) {
+	rows, cols = int(termRows.Load()), int(termCols.Load())
+	if rows == 0 || cols == 0 {
+		return 24, 80
+	}
+	return rows, cols
+}
+
+// Write passes p through and records it as an output event, preceded by a
+// resize event if the terminal changed size since the last write. Events
+// go straight to the file so a game that is killed still leaves a usable
+// recording. Errors writing the recording don't stop the game; they are
+// returned by Close.
+func (r *CastRecorder) Write(p []byte) (int, error) {
+	n, err := r.w.Write(p)
+
+	elapsed := time.Since(r.start).Seconds()
+	if rows, cols := castSize(); rows != r.rows || cols != r.cols {
+		r.rows, r.cols = rows, cols
+		r.event(elapsed, "r", fmt.Sprintf("%dx%d", cols, rows))
+	}
+	r.event(elapsed, "o", string(p[:n]))
+	return n, err
+}
+
+func (r *CastRecorder) event(elapsed float64, code, data string) {
+	line, err := json.Marshal([]any{elapsed, code, data})
+	if err == nil {
+		_, err = r.f.Write(append(line, '\n'))
+	}
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// Close finishes the recording
+func (r *CastRecorder) Close() error {
+	err := r.f.Close()
+	if r.err != nil {
+		return r.err
+	}
+	return err
+}
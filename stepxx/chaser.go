@@ -3,16 +3,20 @@ package main
 // Chaser is a smart enemy! 0.0
 type Chaser struct {
 	Ghost
+
+	// path is what is left of the route planned on the last move, starting
+	// from the chaser's position
 	path []string
 }
 
-var chaserPath []string
-
 // NewChaser creates a new ghost
 func NewChaser(row, col int, img string) *Chaser {
 	var c Chaser
 	c.position = Point{row, col}
+	c.origin = Point{row, col}
+	c.kind = "chaser"
 	c.img = img
+	c.status = GhostStatusNormal
 	return &c
 }
 
@@ -22,192 +26,97 @@ func (c *Chaser) Pos() (row, col int) {
 	return
 }
 
-func (c *Chaser) Img() string {
-	return c.img
+func (c *Chaser) Move(g *Game) {
+	dir := c.drawDirection(g)
+	c.position = makeMove(g.maze, c.position, dir)
+	if len(c.path) > 0 {
+		c.path = c.path[1:]
+	}
 }
 
-func (c *Chaser) Move() {
-	dir := c.drawDirection()
-	c.position = makeMove(c.position, dir)
-
-	for _, s := range sprites {
-		switch p := s.(type) {
-		case *Player:
-			if p.position == c.position {
-				p.Kill()
-				c.path = nil
-			}
-		}
+// target returns the tile the chaser is heading for: the player, or while
+// it is blue its home in the ghost house. A blue chaser that made it home
+// has no target and runs around like any other ghost.
+func (c *Chaser) target(g *Game) (Point, bool) {
+	if c.status != GhostStatusBlue {
+		return g.player.position, true
 	}
+	if c.position != c.origin {
+		return c.origin, true
+	}
+	return Point{}, false
 }
 
-type Node struct {
-	Point
-	h int
-	g int
-}
+// drawDirection plans a fresh route every tick, so the chaser never follows
+// a stale path towards where the player used to be
+func (c *Chaser) drawDirection(g *Game) string {
+	target, ok := c.target(g)
+	if !ok {
+		c.path = nil
+		return c.Ghost.drawDirection(g.rng)
+	}
 
-func (c *Chaser) drawDirection() string {
+	c.path = c.plan(g, target)
 	if len(c.path) == 0 {
-		target := player.position
-		c.path = c.find(c.position, target)
+		// already there, or there is no way to get there
+		return ""
 	}
-	dir := c.path[0]
-	c.path = c.path[1:]
-	return dir
+	return c.path[0]
 }
 
-// implements A* pathfinding algorithm
-//
-// move cost = 1
-// no diagonal movement allowed
-// heuristic = manhattan distance
-func (c *Chaser) find(origin Point, target Point) []string {
-	var pf PathFinder
-	path := pf.walk(origin, target)
-
-	var directions []string
-	current := origin
-	for len(path) > 0 {
-		next := path[len(path)-1]
-		path = path[:len(path)-1]
-		directions = append(directions, giveDirection(current, next))
-		current = next
+// plan returns the directions to follow from the chaser's position to
+// target. Chasing the player uses the flow field shared by every ghost,
+// going home uses A*.
+func (c *Chaser) plan(g *Game, target Point) []string {
+	if target == g.player.position {
+		return followField(g.maze, g.flowField(), c.position)
 	}
-	chaserPath = directions
-
-	// return []string{c.Ghost.drawDirection()}
-	return directions
+	return c.find(g, c.position, target)
 }
 
-func giveDirection(curr, dest Point) string {
-	switch {
-	case curr.row-dest.row == 0 && dest.col-curr.col == 1:
-		return "RIGHT"
-	case curr.row-dest.row == 0 && dest.col-curr.col == -1:
-		return "LEFT"
-	case curr.col-dest.col == 0 && dest.row-curr.row == 1:
-		return "DOWN"
-	case curr.col-dest.col == 0 && dest.row-curr.row == -1:
-		return "UP"
-	default:
-		return "NOP"
+// followField walks downhill on a distance map from p until it reaches the
+// map's target
+func followField(maze Maze, field [][]int, p Point) []string {
+	if field[p.row][p.col] < 0 {
+		return nil
 	}
-}
-
-type PathFinder struct {
-	open   PointSet
-	closed PointSet
-	table  map[Point]PointInfo
-}
-
-type PointSet map[Point]bool
-type PointInfo struct {
-	g      int
-	h      int
-	parent *Point
-}
-
-func (p PointInfo) Cost() int {
-	return p.g + p.h
-}
 
-func (pf *PathFinder) nextPoint() *Point {
-	var min *Point
-	for k := range pf.open {
-		if min == nil {
-			min = &k
-			continue
-		}
-		if pf.table[k].Cost() < pf.table[*min].Cost() {
-			min = &k
+	var route []string
+	for field[p.row][p.col] > 0 {
+		for _, dir := range directions {
+			n := makeMove(maze, p, dir)
+			if n != p && field[n.row][n.col] == field[p.row][p.col]-1 {
+				route = append(route, dir)
+				p = n
+				break
+			}
 		}
 	}
-	return min
-}
-
-func abs(n int) int {
-	y := n >> 63
-	return (n ^ y) - y
-}
-
-func distance(p1, p2 Point) int {
-	return abs(p1.row-p2.row) + abs(p1.col-p2.col)
+	return route
 }
 
-func (pf *PathFinder) isClosed(p Point) bool {
-	_, ok := pf.closed[p]
-	return ok
-}
-
-func (pf *PathFinder) isOpen(p Point) bool {
-	_, ok := pf.open[p]
-	return ok
-}
-
-func (pf *PathFinder) walk(start Point, target Point) []Point {
-	var path []Point
-
-	pf.open = make(PointSet)
-	pf.closed = make(PointSet)
-	pf.table = make(map[Point]PointInfo)
+// find returns the directions to follow to get from origin to target along
+// a shortest path
+func (c *Chaser) find(g *Game, origin Point, target Point) []string {
+	pf := PathFinder{maze: g.maze, distances: g.distances}
+	path := pf.walk(origin, target)
 
-	pf.open[start] = true
-	pf.table[start] = PointInfo{
-		h: distance(start, target),
+	var route []string
+	current := origin
+	for _, next := range path {
+		route = append(route, giveDirection(g.maze, current, next))
+		current = next
 	}
-	for current := pf.nextPoint(); current != nil; current = pf.nextPoint() {
-		var neighbors []Point
-		if up, err := current.Up(); err == nil {
-			neighbors = append(neighbors, up)
-		}
-		if down, err := current.Down(); err == nil {
-			neighbors = append(neighbors, down)
-		}
-		if left, err := current.Left(); err == nil {
-			neighbors = append(neighbors, left)
-		}
-		if right, err := current.Right(); err == nil {
-			neighbors = append(neighbors, right)
-		}
-
-		for _, n := range neighbors {
-			switch {
-			case n == target:
-				// found path
+	return route
+}
 
-				path = append(path, n)
-				for pf.table[*current].parent != nil {
-					path = append(path, *current)
-					current = pf.table[*current].parent
-				}
-				return path
-			case pf.isClosed(n):
-				// do nothing
-			case pf.isOpen(n):
-				info := PointInfo{
-					g:      pf.table[*current].g + 1,
-					h:      distance(n, target),
-					parent: current,
-				}
-				if pf.table[n].Cost() > info.Cost() {
-					// found a better cost to n
-					pf.table[n] = info
-				}
-			default:
-				pf.open[n] = true
-				pf.table[n] = PointInfo{
-					g:      pf.table[*current].g + 1,
-					h:      distance(n, target),
-					parent: current,
-				}
-			}
+// giveDirection returns the move that takes a sprite from curr to the
+// adjacent dest, including moves that wrap around the edges of the maze
+func giveDirection(maze Maze, curr, dest Point) string {
+	for _, dir := range directions {
+		if makeMove(maze, curr, dir) == dest {
+			return dir
 		}
-		// done with current
-		// add to closed list
-		// remove from open list
-		pf.closed[*current] = true
-		delete(pf.open, *current)
 	}
-	return path
+	return ""
 }
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Config holds the game settings: the glyph for every kind of tile, how
+// they are drawn, and the rules set by the difficulty
+type Config struct {
+	Player           string `json:"player"`
+	Ghost            string `json:"ghost"`
+	GhostBlue        string `json:"ghost_blue"`
+	Wall             string `json:"wall"`
+	Dot              string `json:"dot"`
+	Pill             string `json:"pill"`
+	Fruit            string `json:"fruit"`
+	Death            string `json:"death"`
+	Space            string `json:"space"`
+	Chaser           string `json:"chaser"`
+	WallStyle        string `json:"wall_style"`
+	Theme            string `json:"theme"`
+	UseEmoji         bool   `json:"use_emoji"`
+	Difficulty       string `json:"difficulty"`
+	Lives            int    `json:"lives"`
+	FrameRate        int    `json:"frame_rate"`
+	GhostSpeed       int    `json:"ghost_speed"`
+	PillDurationSecs int    `json:"pill_duration_secs"`
+	Ghosts           string `json:"ghosts"`
+	ExtraLifeScore   int    `json:"extra_life_score"`
+	ExtraLifeEvery   int    `json:"extra_life_every"`
+	MaxLives         int    `json:"max_lives"`
+}
+
+// Limits of the rules
+const (
+	maxFrameRate  = 30
+	maxLives      = 9
+	maxGhostSpeed = 200
+)
+
+// defaultConfig provides every setting missing from the configuration
+// file. It only uses ASCII, so it works on any terminal. The rules come
+// from the difficulty preset.
+var defaultConfig = Config{
+	Player:     "P",
+	Ghost:      "G",
+	GhostBlue:  "B",
+	Wall:       "#",
+	Dot:        ".",
+	Pill:       "X",
+	Fruit:      "F",
+	Death:      "x",
+	Space:      " ",
+	Chaser:     "C",
+	WallStyle:  wallSolid,
+	Theme:      "classic",
+	Difficulty: "normal",
+	MaxLives:   6,
+}
+
+var cfg Config
+
+// configFlag collects the settings given with -set on the command line, in
+// order
+type configFlag struct {
+	sets [][2]string
+}
+
+func (f *configFlag) String() string {
+	return ""
+}
+
+func (f *configFlag) Set(value string) error {
+	name, v, ok := strings.Cut(value, "=")
+	if !ok {
+		return errors.New("expected name=value")
+	}
+	f.sets = append(f.sets, [2]string{name, v})
+	return nil
+}
+
+// loadConfig works out the configuration in layers: the defaults, then the
+// configuration file, then PACGO_* environment variables (e.g.
+// PACGO_FRAME_RATE=10) and finally -set flags, each overriding the one
+// before. The difficulty preset then fills in the rules that none of the
+// layers set.
+func loadConfig() error {
+	c := defaultConfig
+	explicit := make(map[string]bool)
+
+	err := decodeConfigFile(*configFile, &c, explicit)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range configNames() {
+		env := "PACGO_" + strings.ToUpper(name)
+		if value, ok := os.LookupEnv(env); ok {
+			if err := setConfigField(&c, name, value); err != nil {
+				return fmt.Errorf("%s: %w", env, err)
+			}
+			explicit[name] = true
+		}
+	}
+
+	for _, set := range configSets.sets {
+		if err := setConfigField(&c, set[0], set[1]); err != nil {
+			return fmt.Errorf("-set %s: %w", set[0], err)
+		}
+		explicit[set[0]] = true
+	}
+
+	if *difficulty != "" {
+		c.Difficulty = *difficulty
+	}
+	preset, err := findDifficulty(c.Difficulty)
+	if err != nil {
+		return fmt.Errorf("difficulty: %w", err)
+	}
+	preset.apply(&c, explicit)
+
+	t, err := c.validate()
+	if err != nil {
+		return err
+	}
+	cfg, theme = c, t
+	return nil
+}
+
+// decodeConfigFile reads the settings in the file at path, or the built-in
+// configuration if path is empty, into c, and marks the ones it sets in
+// explicit. Settings the file leaves out keep what is already in c, and
+// unknown settings are an error since they are most likely typos.
+func decodeConfigFile(path string, c *Config, explicit map[string]bool) error {
+	f, err := openAsset(path, defaultConfigFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if path == "" {
+		path = "built-in " + defaultConfigFile
+	}
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for name := range keys {
+		explicit[name] = true
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(c)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if decoder.More() {
+		return fmt.Errorf("%s: unexpected data after the configuration", path)
+	}
+	return nil
+}
+
+// validate checks that every setting makes sense and returns the theme c
+// asks for
+func (c *Config) validate() (Theme, error) {
+	for _, g := range c.glyphs() {
+		if *g.glyph == "" {
+			return Theme{}, fmt.Errorf("%s: glyph must not be empty", g.name)
+		}
+	}
+
+	if c.WallStyle != wallSolid && c.WallStyle != wallBox {
+		return Theme{}, fmt.Errorf("wall_style: must be %q or %q, not %q", wallSolid, wallBox, c.WallStyle)
+	}
+	if c.FrameRate < 1 || c.FrameRate > maxFrameRate {
+		return Theme{}, fmt.Errorf("frame_rate: must be between 1 and %d, not %d", maxFrameRate, c.FrameRate)
+	}
+	if c.MaxLives < 1 || c.MaxLives > maxLives {
+		return Theme{}, fmt.Errorf("max_lives: must be between 1 and %d, not %d", maxLives, c.MaxLives)
+	}
+	if c.Lives < 1 || c.Lives > maxLives {
+		return Theme{}, fmt.Errorf("lives: must be between 1 and %d, not %d", maxLives, c.Lives)
+	}
+	if c.Lives > c.MaxLives {
+		return Theme{}, fmt.Errorf("lives: must not be more than max_lives (%d), not %d", c.MaxLives, c.Lives)
+	}
+	if c.GhostSpeed < 1 || c.GhostSpeed > maxGhostSpeed {
+		return Theme{}, fmt.Errorf("ghost_speed: must be between 1 and %d, not %d", maxGhostSpeed, c.GhostSpeed)
+	}
+	if c.PillDurationSecs < 0 {
+		return Theme{}, fmt.Errorf("pill_duration_secs: must not be negative")
+	}
+	if _, ok := ghostStrategies[c.Ghosts]; !ok {
+		return Theme{}, fmt.Errorf("ghosts: must be maze, random or chaser, not %q", c.Ghosts)
+	}
+	if c.ExtraLifeScore < 0 {
+		return Theme{}, fmt.Errorf("extra_life_score: must not be negative")
+	}
+	if c.ExtraLifeEvery < 0 {
+		return Theme{}, fmt.Errorf("extra_life_every: must not be negative")
+	}
+
+	t, err := findTheme(c.Theme)
+	if err != nil {
+		return Theme{}, fmt.Errorf("theme: %w", err)
+	}
+	return t, nil
+}
+
+// glyphSetting is a setting holding the glyph for a kind of tile
+type glyphSetting struct {
+	name     string
+	glyph    *string
+	fallback string // ASCII glyph to use instead
+}
+
+// glyphs returns every glyph setting of c
+func (c *Config) glyphs() []glyphSetting {
+	return []glyphSetting{
+		{"player", &c.Player, defaultConfig.Player},
+		{"ghost", &c.Ghost, defaultConfig.Ghost},
+		{"ghost_blue", &c.GhostBlue, defaultConfig.GhostBlue},
+		{"chaser", &c.Chaser, defaultConfig.Chaser},
+		{"wall", &c.Wall, defaultConfig.Wall},
+		{"dot", &c.Dot, defaultConfig.Dot},
+		{"pill", &c.Pill, defaultConfig.Pill},
+		{"fruit", &c.Fruit, defaultConfig.Fruit},
+		{"death", &c.Death, defaultConfig.Death},
+		{"space", &c.Space, defaultConfig.Space},
+	}
+}
+
+// configNames returns the JSON name of every setting
+func configNames() []string {
+	var names []string
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		names = append(names, t.Field(i).Tag.Get("json"))
+	}
+	return names
+}
+
+// setConfigField sets the setting called name, as in the configuration
+// file, from its text form
+func setConfigField(c *Config, name, value string) error {
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).Tag.Get("json") != name {
+			continue
+		}
+
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(value)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%q is not true or false", value)
+			}
+			field.SetBool(b)
+		case reflect.Int:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("%q is not a whole number", value)
+			}
+			field.SetInt(int64(n))
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown setting %q", name)
+}
+
+// runConfig is the config command. "config print" shows the effective
+// configuration, after applying every layer.
+func runConfig(args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		return errors.New("usage: config print")
+	}
+
+	err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "    ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	oldCfg, oldTheme := cfg, theme
+	oldFile, oldSets, oldDifficulty := *configFile, configSets, *difficulty
+	defer func() {
+		cfg, theme = oldCfg, oldTheme
+		*configFile, configSets, *difficulty = oldFile, oldSets, oldDifficulty
+	}()
+
+	tests := []struct {
+		name       string
+		file       string // contents of the configuration file
+		env        map[string]string
+		sets       []string
+		difficulty string
+
+		lives, maxLives, frameRate int
+		wantErr                    string
+	}{
+		{
+			name:  "preset fills in the rules",
+			file:  `{}`,
+			lives: 3, maxLives: 6, frameRate: 5,
+		},
+		{
+			name:  "file overrides the preset",
+			file:  `{"lives": 4, "frame_rate": 10}`,
+			lives: 4, maxLives: 6, frameRate: 10,
+		},
+		{
+			name:  "environment overrides the file",
+			file:  `{"lives": 4}`,
+			env:   map[string]string{"PACGO_LIVES": "5"},
+			lives: 5, maxLives: 6, frameRate: 5,
+		},
+		{
+			name:  "-set overrides the environment",
+			file:  `{"lives": 4}`,
+			env:   map[string]string{"PACGO_LIVES": "5"},
+			sets:  []string{"lives=2"},
+			lives: 2, maxLives: 6, frameRate: 5,
+		},
+		{
+			name:       "--difficulty picks the preset",
+			file:       `{"difficulty": "easy"}`,
+			difficulty: "hard",
+			lives:      2, maxLives: 6, frameRate: 7,
+		},
+		{
+			name:       "preset lives are capped at max_lives",
+			file:       `{"max_lives": 3}`,
+			difficulty: "easy",
+			lives:      3, maxLives: 3, frameRate: 4,
+		},
+		{
+			name:    "more lives than max_lives",
+			file:    `{}`,
+			sets:    []string{"lives=7"},
+			wantErr: "lives: must not be more than max_lives (6), not 7",
+		},
+		{
+			name:    "explicit lives above a lower max_lives",
+			file:    `{"max_lives": 3}`,
+			env:     map[string]string{"PACGO_LIVES": "4"},
+			wantErr: "lives: must not be more than max_lives (3), not 4",
+		},
+		{
+			name:    "unknown setting",
+			file:    `{"live": 4}`,
+			wantErr: `unknown field "live"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(tt.file), 0644); err != nil {
+				t.Fatal(err)
+			}
+			*configFile = path
+			*difficulty = tt.difficulty
+			configSets = configFlag{}
+			for _, set := range tt.sets {
+				configSets.Set(set)
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			err := loadConfig()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadConfig() = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Lives != tt.lives || cfg.MaxLives != tt.maxLives || cfg.FrameRate != tt.frameRate {
+				t.Errorf("lives %d/%d at %d fps, want %d/%d at %d fps",
+					cfg.Lives, cfg.MaxLives, cfg.FrameRate, tt.lives, tt.maxLives, tt.frameRate)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Controller steers the player instead of the keyboard
+type Controller interface {
+	// Direction is called once per tick and returns where the player should
+	// go next: "UP", "DOWN", "LEFT", "RIGHT", or "" to stay put
+	Direction(v View) string
+}
+
+// controllers maps names usable with --player-controller to constructors.
+// Controllers that make random choices draw them from seed, so a match
+// plays out the same every time for the same seed.
+var controllers = map[string]func(seed int64) Controller{}
+
+// RegisterController makes a controller available by name
+func RegisterController(name string, factory func(seed int64) Controller) {
+	if _, ok := controllers[name]; ok {
+		panic("controller already registered: " + name)
+	}
+	controllers[name] = factory
+}
+
+// NewController creates the controller registered under name, seeded with
+// seed
+func NewController(name string, seed int64) (Controller, error) {
+	factory, ok := controllers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown controller %q, available: %s", name, strings.Join(controllerNames(), ", "))
+	}
+	return factory(seed), nil
+}
+
+func controllerNames() []string {
+	var names []string
+	for name := range controllers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// View is a read-only view of a game, handed to controllers every tick
+type View struct {
+	g *Game
+}
+
+// GhostView is what a controller can see of a ghost
+type GhostView struct {
+	Position Point
+	Blue     bool
+}
+
+// Tile returns the maze character at p
+func (v View) Tile(p Point) byte {
+	return v.g.maze[p.row][p.col]
+}
+
+// Rows returns the height of the maze
+func (v View) Rows() int {
+	return len(v.g.maze)
+}
+
+// Player returns the player's position
+func (v View) Player() Point {
+	return v.g.player.position
+}
+
+// Ghosts returns the position and status of every ghost
+func (v View) Ghosts() []GhostView {
+	var ghosts []GhostView
+	for _, s := range v.g.sprites {
+		switch s := s.(type) {
+		case *Ghost:
+			ghosts = append(ghosts, GhostView{s.position, s.status == GhostStatusBlue})
+		case *Chaser:
+			ghosts = append(ghosts, GhostView{s.position, s.status == GhostStatusBlue})
+		}
+	}
+	return ghosts
+}
+
+// Move returns where a step in dir from p would end up, and false if there
+// is a wall in the way
+func (v View) Move(p Point, dir string) (Point, bool) {
+	next := makeMove(v.g.maze, p, dir)
+	return next, next != p
+}
+
+// directions are the moves available to every sprite, in a fixed order so
+// that controllers behave deterministically
+var directions = []string{"UP", "DOWN", "LEFT", "RIGHT"}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// drawDebug draws what the ghosts are up to over the maze: the route each
+// chaser plans to follow, marked with the ghost's number, and the tile it is
+// heading for in reverse video. A panel in the top left corner lists every
+// ghost's target, mode and speed, with the player's input and the tick.
+func drawDebug(w io.Writer, g *Game, v viewport) {
+	if v.tooSmall() {
+		return
+	}
+
+	input := g.input
+	if input == "" {
+		input = "-"
+	}
+	lines := []string{fmt.Sprintf("Tick: %-6d Input: %-5s", g.stats.Ticks, input)}
+
+	number := 0
+	for _, s := range g.sprites {
+		if s == Sprite(g.player) {
+			continue
+		}
+		number++
+		mark := spriteStyle(s).paint(debugTile(fmt.Sprint(number % 10)))
+
+		var ghost *Ghost
+		var target Point
+		var ok bool
+		switch s := s.(type) {
+		case *Chaser:
+			ghost = &s.Ghost
+			target, ok = s.target(g)
+			p := s.position
+			for _, dir := range s.path {
+				p = makeMove(g.maze, p, dir)
+				if v.contains(p) && spriteAt(g, p) == nil {
+					v.moveCursor(w, p)
+					fmt.Fprint(w, mark)
+				}
+			}
+		case *Ghost:
+			ghost = s
+		}
+
+		targetText := "-"
+		if ok {
+			targetText = fmt.Sprintf("%d,%d", target.row, target.col)
+			drawDebugTarget(w, g, v, target)
+		}
+		lines = append(lines, fmt.Sprintf("%d %-6s at %2d,%-3d target %-6s %-6s speed %d%%",
+			number, ghost.kind, ghost.position.row, ghost.position.col, targetText, strings.ToLower(string(ghost.status)), g.rules.GhostSpeed))
+	}
+
+	drawDebugPanel(w, v, lines)
+}
+
+// drawDebugPanel writes lines to the right of the maze if the terminal has
+// room for them, and over its top left corner otherwise
+func drawDebugPanel(w io.Writer, v viewport, lines []string) {
+	widest := 0
+	for _, line := range lines {
+		widest = max(widest, len(line))
+	}
+
+	left := v.width() + 2
+	cols := left + widest
+	if termWidth := int(termCols.Load()); termWidth > 0 && termWidth < left+widest {
+		left, cols = 0, max(termWidth, v.width())
+	}
+
+	for i, line := range lines[:min(len(lines), v.rows)] {
+		if len(line) > cols-left {
+			line = line[:cols-left]
+		}
+		moveCursorColumn(w, i, left)
+		fmt.Fprint(w, theme.HUD.paint(line))
+	}
+}
+
+// drawDebugTarget shows the target tile in reverse video, along with
+// whatever is on it
+func drawDebugTarget(w io.Writer, g *Game, v viewport, target Point) {
+	if !v.contains(target) {
+		return
+	}
+
+	style, text := Style{}, debugTile("+")
+	if s := spriteAt(g, target); s != nil {
+		style, text = spriteStyle(s), s.Img()
+	}
+	style.Reverse = true
+	v.moveCursor(w, target)
+	fmt.Fprint(w, style.paint(text))
+}
+
+// debugTile pads text to the width of a maze tile
+func debugTile(text string) string {
+	if cfg.UseEmoji {
+		return text + " "
+	}
+	return text
+}
+
+// spriteAt returns the sprite drawn on p, or nil if there is none
+func spriteAt(g *Game, p Point) Sprite {
+	var found Sprite
+	for _, s := range g.sprites {
+		if row, col := s.Pos(); (Point{row, col}) == p {
+			// sprites later in the list are drawn on top
+			found = s
+		}
+	}
+	return found
+}
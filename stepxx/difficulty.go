@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Difficulty is a preset for the settings that make the game easier or
+// harder. Settings given explicitly in the configuration, the environment
+// or with -set win over the preset.
+type Difficulty struct {
+	Lives            int
+	FrameRate        int
+	GhostSpeed       int // percent of the player's speed
+	PillDurationSecs int
+	Ghosts           string // ghost strategy, as in the tournament
+	ExtraLifeScore   int    // 0 for no extra lives
+	ExtraLifeEvery   int    // 0 for only one extra life
+}
+
+// difficulties are the presets that can be chosen with --difficulty
+var difficulties = map[string]Difficulty{
+	"easy": {
+		Lives:            5,
+		FrameRate:        4,
+		GhostSpeed:       75,
+		PillDurationSecs: 15,
+		Ghosts:           "random",
+		ExtraLifeScore:   100,
+		ExtraLifeEvery:   100,
+	},
+	"normal": {
+		Lives:            3,
+		FrameRate:        5,
+		GhostSpeed:       100,
+		PillDurationSecs: 10,
+		Ghosts:           "maze",
+		ExtraLifeScore:   200,
+	},
+	"hard": {
+		Lives:            2,
+		FrameRate:        7,
+		GhostSpeed:       100,
+		PillDurationSecs: 6,
+		Ghosts:           "maze",
+		ExtraLifeScore:   300,
+	},
+	"nightmare": {
+		Lives:            1,
+		FrameRate:        8,
+		GhostSpeed:       125,
+		PillDurationSecs: 3,
+		Ghosts:           "chaser",
+	},
+}
+
+// findDifficulty returns the preset called name
+func findDifficulty(name string) (Difficulty, error) {
+	d, ok := difficulties[name]
+	if !ok {
+		var names []string
+		for n := range difficulties {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return Difficulty{}, fmt.Errorf("unknown difficulty %q, choose one of %s", name, strings.Join(names, ", "))
+	}
+	return d, nil
+}
+
+// apply copies the preset into c, except for the settings named in
+// explicit. The preset's lives are capped at the configured max_lives.
+func (d Difficulty) apply(c *Config, explicit map[string]bool) {
+	if !explicit["lives"] {
+		c.Lives = min(d.Lives, c.MaxLives)
+	}
+	if !explicit["frame_rate"] {
+		c.FrameRate = d.FrameRate
+	}
+	if !explicit["ghost_speed"] {
+		c.GhostSpeed = d.GhostSpeed
+	}
+	if !explicit["pill_duration_secs"] {
+		c.PillDurationSecs = d.PillDurationSecs
+	}
+	if !explicit["ghosts"] {
+		c.Ghosts = d.Ghosts
+	}
+	if !explicit["extra_life_score"] {
+		c.ExtraLifeScore = d.ExtraLifeScore
+	}
+	if !explicit["extra_life_every"] {
+		c.ExtraLifeEvery = d.ExtraLifeEvery
+	}
+}
+
+// withDifficultyGhosts returns maze with the ghosts the configuration asks
+// for
+func withDifficultyGhosts(maze Maze) Maze {
+	return withGhosts(maze, ghostStrategies[cfg.Ghosts])
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// Rewards are what an agent earns for each kind of event during a step
+type Rewards struct {
+	Dot        float64 `json:"dot"`
+	Pill       float64 `json:"pill"`
+	GhostEaten float64 `json:"ghost_eaten"`
+	Death      float64 `json:"death"`
+	LevelClear float64 `json:"level_clear"`
+	Tick       float64 `json:"tick"`
+}
+
+var defaultRewards = Rewards{
+	Dot:        1,
+	Pill:       10,
+	GhostEaten: 20,
+	Death:      -100,
+	LevelClear: 100,
+}
+
+// Observation channels, in the order they appear in Observation.Grid
+var envChannels = []string{"wall", "dot", "pill", "player", "ghost", "blue_ghost"}
+
+// envRequest is a single line read from stdin. Cmd is either "reset" or
+// "step"; the other fields only apply to one of them.
+type envRequest struct {
+	Cmd     string          `json:"cmd"`
+	Seed    int64           `json:"seed"`
+	Maze    string          `json:"maze"`
+	Rewards json.RawMessage `json:"rewards"` // overrides some or all of defaultRewards
+	Action  string          `json:"action"`
+}
+
+// envResponse is a single line written to stdout
+type envResponse struct {
+	Observation *Observation `json:"observation,omitempty"`
+	Reward      float64      `json:"reward"`
+	Done        bool         `json:"done"`
+	Info        *envInfo     `json:"info,omitempty"`
+	Error       string       `json:"error,omitempty"`
+}
+
+type envInfo struct {
+	Stats    Stats `json:"stats"`
+	DotsLeft int   `json:"dots_left"`
+	Score    int   `json:"score"`
+	Lives    int   `json:"lives"`
+	Cleared  bool  `json:"cleared"`
+}
+
+// Observation is what an agent sees of the game after each reset or step
+type Observation struct {
+	// Grid is indexed by [channel][row][col], with 1 where the channel's
+	// feature is present and 0 otherwise
+	Grid     [][][]int   `json:"grid"`
+	Channels []string    `json:"channels"`
+	Player   envPosition `json:"player"`
+	Ghosts   []envGhost  `json:"ghosts"`
+}
+
+type envPosition struct {
+	Row int `json:"row"`
+	Col int `json:"col"`
+}
+
+type envGhost struct {
+	envPosition
+	Kind string `json:"kind"`
+	Blue bool   `json:"blue"`
+}
+
+// env is a reinforcement learning environment driving a single game
+type env struct {
+	rewards Rewards
+	game    *Game
+}
+
+// runEnv speaks a JSON-lines protocol on stdin and stdout, one response per
+// request:
+//
+//	{"cmd": "reset", "seed": 42, "maze": "maze01.txt", "rewards": {...}}
+//	{"cmd": "step", "action": "UP"}
+//
+// Both reply with an observation and info; step also returns the reward and
+// whether the episode is done. Failed requests get {"error": "..."}.
+func runEnv(args []string) error {
+	flags := flag.NewFlagSet("env", flag.ExitOnError)
+	flags.Parse(args)
+
+	err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+
+	var e env
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	encoder := json.NewEncoder(os.Stdout)
+
+	for scanner.Scan() {
+		var req envRequest
+		var resp envResponse
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			resp.Error = "invalid request: " + err.Error()
+		} else if resp, err = e.handle(req); err != nil {
+			resp = envResponse{Error: err.Error()}
+		}
+
+		if err := encoder.Encode(resp); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+func (e *env) handle(req envRequest) (envResponse, error) {
+	switch req.Cmd {
+	case "reset":
+		return e.reset(req)
+	case "step":
+		return e.step(req.Action)
+	default:
+		return envResponse{}, fmt.Errorf("unknown command %q", req.Cmd)
+	}
+}
+
+func (e *env) reset(req envRequest) (envResponse, error) {
+	path := req.Maze
+	if path == "" {
+		path = *mazeFile
+	}
+
+	maze, err := loadMaze(path)
+	if err != nil {
+		return envResponse{}, err
+	}
+
+	rewards := defaultRewards
+	if req.Rewards != nil {
+		err = json.Unmarshal(req.Rewards, &rewards)
+		if err != nil {
+			return envResponse{}, fmt.Errorf("rewards: %w", err)
+		}
+	}
+	e.rewards = rewards
+	e.game = NewGame(withDifficultyGhosts(maze), req.Seed)
+
+	return envResponse{
+		Observation: e.observe(),
+		Info:        e.info(),
+	}, nil
+}
+
+func (e *env) step(action string) (envResponse, error) {
+	if e.game == nil {
+		return envResponse{}, errors.New("no episode in progress, send reset first")
+	}
+	if e.game.Over() {
+		return envResponse{}, errors.New("episode is over, send reset to start a new one")
+	}
+
+	switch action {
+	case "UP", "DOWN", "LEFT", "RIGHT", "":
+	case "NONE":
+		action = ""
+	default:
+		return envResponse{}, fmt.Errorf("invalid action %q", action)
+	}
+
+	before := e.game.stats
+	e.game.Update(action)
+	after := e.game.stats
+
+	r := e.rewards
+	reward := r.Tick +
+		r.Dot*float64(after.Dots-before.Dots) +
+		r.Pill*float64(after.Pills-before.Pills) +
+		r.GhostEaten*float64(after.GhostsEaten-before.GhostsEaten) +
+		r.Death*float64(after.Deaths-before.Deaths)
+	if e.game.numDots == 0 {
+		reward += r.LevelClear
+	}
+
+	return envResponse{
+		Observation: e.observe(),
+		Reward:      reward,
+		Done:        e.game.Over(),
+		Info:        e.info(),
+	}, nil
+}
+
+func (e *env) info() *envInfo {
+	return &envInfo{
+		Stats:    e.game.stats,
+		DotsLeft: e.game.numDots,
+		Score:    e.game.player.score,
+		Lives:    e.game.player.lives,
+		Cleared:  e.game.numDots == 0,
+	}
+}
+
+func (e *env) observe() *Observation {
+	g := e.game
+
+	width := g.maze.Width()
+
+	obs := Observation{
+		Grid:     make([][][]int, len(envChannels)),
+		Channels: envChannels,
+	}
+	for ch := range obs.Grid {
+		obs.Grid[ch] = make([][]int, len(g.maze))
+		for row := range g.maze {
+			obs.Grid[ch][row] = make([]int, width)
+		}
+	}
+
+	for row, line := range g.maze {
+		for col, char := range []byte(line) {
+			switch char {
+			case '#':
+				obs.Grid[0][row][col] = 1
+			case '.':
+				obs.Grid[1][row][col] = 1
+			case 'X':
+				obs.Grid[2][row][col] = 1
+			}
+		}
+	}
+
+	for _, s := range g.sprites {
+		row, col := s.Pos()
+		switch s := s.(type) {
+		case *Player:
+			obs.Player = envPosition{row, col}
+			obs.Grid[3][row][col] = 1
+		case *Ghost:
+			obs.Ghosts = append(obs.Ghosts, newEnvGhost(row, col, "ghost", s.status))
+		case *Chaser:
+			obs.Ghosts = append(obs.Ghosts, newEnvGhost(row, col, "chaser", s.status))
+		}
+	}
+	for _, ghost := range obs.Ghosts {
+		if ghost.Blue {
+			obs.Grid[5][ghost.Row][ghost.Col] = 1
+		} else {
+			obs.Grid[4][ghost.Row][ghost.Col] = 1
+		}
+	}
+
+	return &obs
+}
+
+func newEnvGhost(row, col int, kind string, status GhostStatus) envGhost {
+	return envGhost{
+		envPosition: envPosition{row, col},
+		Kind:        kind,
+		Blue:        status == GhostStatusBlue,
+	}
+}
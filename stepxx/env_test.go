@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+func TestEnvResetRewards(t *testing.T) {
+	if err := loadConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	partial := defaultRewards
+	partial.Dot = 5
+
+	tests := []struct {
+		name    string
+		rewards string
+		want    Rewards
+		wantErr bool
+	}{
+		{"none", "", defaultRewards, false},
+		{"partial", `{"dot":5}`, partial, false},
+		{"all", `{"dot":2,"pill":3,"ghost_eaten":4,"death":-5,"level_clear":6,"tick":-0.5}`,
+			Rewards{Dot: 2, Pill: 3, GhostEaten: 4, Death: -5, LevelClear: 6, Tick: -0.5}, false},
+		{"invalid", `{"dot":"five"}`, Rewards{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var e env
+			req := envRequest{Cmd: "reset", Seed: 1}
+			if tt.rewards != "" {
+				req.Rewards = []byte(tt.rewards)
+			}
+
+			_, err := e.reset(req)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("reset with rewards %s succeeded, want an error", tt.rewards)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.rewards != tt.want {
+				t.Errorf("rewards = %+v, want %+v", e.rewards, tt.want)
+			}
+		})
+	}
+}
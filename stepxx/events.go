@@ -0,0 +1,41 @@
+package main
+
+// EventType identifies something that happened during a game
+type EventType string
+
+const (
+	EventDotEaten       EventType = "dot_eaten"
+	EventPillEaten      EventType = "pill_eaten"
+	EventGhostEaten     EventType = "ghost_eaten"
+	EventDeath          EventType = "death"
+	EventLevelClear     EventType = "level_clear"
+	EventFruitCollected EventType = "fruit_collected"
+	EventExtraLife      EventType = "extra_life"
+)
+
+// Event is published by a game when something happens to the player
+type Event struct {
+	Type EventType
+	Tick int
+	// Ghost is the kind of ghost involved in EventGhostEaten and EventDeath
+	Ghost string
+}
+
+// EventBus delivers game events to subscribers. Events are published from
+// inside Game.Update, so subscribers run on the game's goroutine, in the
+// order they subscribed, and must not block.
+type EventBus struct {
+	subscribers []func(Event)
+}
+
+// Subscribe calls fn for every event published from now on
+func (b *EventBus) Subscribe(fn func(Event)) {
+	b.subscribers = append(b.subscribers, fn)
+}
+
+// Publish delivers e to every subscriber
+func (b *EventBus) Publish(e Event) {
+	for _, fn := range b.subscribers {
+		fn(e)
+	}
+}
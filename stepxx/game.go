@@ -0,0 +1,309 @@
+package main
+
+import "math/rand"
+
+// Stats counts what happened during a game
+type Stats struct {
+	Ticks       int `json:"ticks"`
+	Dots        int `json:"dots"`
+	Pills       int `json:"pills"`
+	GhostsEaten int `json:"ghosts_eaten"`
+	Deaths      int `json:"deaths"`
+	Fruits      int `json:"fruits"`
+
+	// DeathsBy counts deaths by the kind of ghost that caught the player
+	DeathsBy map[string]int `json:"deaths_by"`
+}
+
+// Game holds the state of a single game, independently of how it is
+// displayed or where its input comes from
+type Game struct {
+	maze    Maze
+	player  *Player
+	sprites []Sprite
+	numDots int
+	input   string
+	rng     *rand.Rand
+	stats   Stats
+	events  EventBus
+
+	// distances caches shortest path lengths for the pathfinding ghosts
+	distances *DistanceCache
+
+	// field is the flow field towards the player, shared by every ghost
+	// chasing them and recomputed when the player moves
+	field       [][]int
+	fieldTarget Point
+
+	// walls are the box-drawing glyphs for the walls, worked out the first
+	// time the maze is drawn
+	walls WallTiles
+
+	// frightened is the number of ticks left before blue ghosts go back
+	// to normal
+	frightened int
+
+	// fruit is the number of ticks left before the bonus fruit in front of
+	// the ghost house disappears, 0 when there is none
+	fruit int
+
+	// ghostSteps accumulates the ghost speed, in percent of a move per
+	// tick, until the ghosts have earned a move
+	ghostSteps int
+
+	// nextExtraLife is the score at which the player gets another life, 0
+	// when there are no more extra lives to get
+	nextExtraLife int
+
+	// rules is the configuration the game started with. The rules are
+	// read from here rather than the live configuration, which the speed
+	// option and reloading the configuration file change mid-game, so the
+	// game plays out the same when replayed.
+	rules Config
+
+	// start, seed and inputs are, along with the rules, everything needed
+	// to replay the game
+	start  Maze
+	seed   int64
+	inputs []string
+}
+
+// Bonus fruit appears when the player has eaten this many dots
+var fruitDots = []int{70, 170}
+
+const (
+	fruitScore = 100
+	fruitSecs  = 10
+)
+
+// NewGame starts a game on a copy of maze, so the same maze can be reused
+// for the next game. Games with the same maze and seed play out the same
+// given the same input.
+func NewGame(maze Maze, seed int64) *Game {
+	var g Game
+	g.maze = append(Maze(nil), maze...)
+	g.start = maze
+	g.seed = seed
+	g.rules = cfg
+	g.rng = rand.New(rand.NewSource(seed))
+	g.distances = NewDistanceCache(g.maze)
+	g.stats.DeathsBy = make(map[string]int)
+	g.nextExtraLife = g.rules.ExtraLifeScore
+
+	for row, line := range g.maze {
+		for col, char := range line {
+			switch char {
+			case 'P':
+				g.player = NewPlayer(row, col, g.rules.Lives, cfg.Player)
+				g.sprites = append(g.sprites, g.player)
+			case 'G':
+				g.sprites = append(g.sprites, NewGhost(row, col, cfg.Ghost))
+			case 'C':
+				g.sprites = append(g.sprites, NewChaser(row, col, cfg.Chaser))
+			case '.':
+				g.numDots++
+			}
+		}
+	}
+
+	return &g
+}
+
+// Update advances the game by one tick. Every tick runs the same phases in
+// the same order on the caller's goroutine, so a game needs no locking and
+// plays out the same for the same seed and input:
+//
+//  1. blue ghosts turn back to normal when the pill wears off
+//  2. the player moves in the direction given by input, and gets an extra
+//     life if their score reached the next threshold
+//  3. collisions between the player and the ghosts are resolved
+//  4. the ghosts move, in the order they appear in the maze, as many times
+//     as their speed allows this tick (usually once)
+//  5. collisions are resolved after every ghost move
+func (g *Game) Update(input string) {
+	g.stats.Ticks++
+	if g.frightened > 0 {
+		g.frightened--
+		if g.frightened == 0 {
+			g.setGhostStatus(GhostStatusNormal)
+		}
+	}
+	if g.fruit > 0 {
+		g.fruit--
+	}
+
+	g.input = input
+	g.inputs = append(g.inputs, input)
+	g.player.Move(g)
+	g.checkExtraLife()
+	if g.numDots == 0 {
+		g.publish(EventLevelClear, "")
+	}
+	g.collisions()
+	if g.Over() {
+		return
+	}
+
+	g.ghostSteps += g.rules.GhostSpeed
+	for g.ghostSteps >= 100 && !g.Over() {
+		g.ghostSteps -= 100
+		for _, s := range g.sprites {
+			if s != Sprite(g.player) {
+				s.Move(g)
+			}
+		}
+		g.collisions()
+	}
+}
+
+// checkExtraLife gives the player another life each time their score
+// reaches the next threshold, unless they already have as many lives as
+// allowed
+func (g *Game) checkExtraLife() {
+	for g.nextExtraLife > 0 && g.player.score >= g.nextExtraLife {
+		if g.player.lives < g.rules.MaxLives {
+			g.player.lives++
+			g.publish(EventExtraLife, "")
+		}
+
+		if g.rules.ExtraLifeEvery > 0 {
+			g.nextExtraLife += g.rules.ExtraLifeEvery
+		} else {
+			g.nextExtraLife = 0
+		}
+	}
+}
+
+// Over reports whether the game has finished, either because the player
+// cleared the maze or ran out of lives
+func (g *Game) Over() bool {
+	return g.numDots == 0 || g.player.lives <= 0
+}
+
+func (g *Game) publish(t EventType, ghost string) {
+	g.events.Publish(Event{Type: t, Tick: g.stats.Ticks, Ghost: ghost})
+}
+
+// Restyle gives every sprite the glyph the configuration currently has for
+// it, and the walls the style they now have, after the configuration was
+// reloaded
+func (g *Game) Restyle() {
+	g.walls = nil
+	for _, s := range g.sprites {
+		switch s := s.(type) {
+		case *Player:
+			s.img = cfg.Player
+		case *Chaser:
+			s.img = cfg.Chaser
+		case *Ghost:
+			s.img = cfg.Ghost
+		}
+	}
+}
+
+// wallTiles returns the box-drawing glyphs for the walls of the maze. The
+// walls never move, so they are only worked out once per game, or after
+// Restyle.
+func (g *Game) wallTiles() WallTiles {
+	if g.walls == nil {
+		g.walls = NewWallTiles(g.maze)
+	}
+	return g.walls
+}
+
+// FruitPos returns where the bonus fruit is and whether there is one
+func (g *Game) FruitPos() (Point, bool) {
+	return g.player.origin, g.fruit > 0
+}
+
+// eatDot scores a dot and puts out the bonus fruit when enough dots have
+// been eaten
+func (g *Game) eatDot() {
+	g.numDots--
+	g.stats.Dots++
+	g.player.score++
+	g.publish(EventDotEaten, "")
+
+	for _, dots := range fruitDots {
+		if g.stats.Dots == dots {
+			g.fruit = fruitSecs * g.rules.FrameRate
+		}
+	}
+}
+
+// eatFruit collects the bonus fruit if the player is standing on it
+func (g *Game) eatFruit() {
+	if pos, ok := g.FruitPos(); !ok || pos != g.player.position {
+		return
+	}
+	g.fruit = 0
+	g.stats.Fruits++
+	g.player.score += fruitScore
+	g.publish(EventFruitCollected, "")
+}
+
+// flowField returns the distance from every tile to the player
+func (g *Game) flowField() [][]int {
+	if g.field == nil || g.fieldTarget != g.player.position {
+		g.field = g.distances.Field(g.player.position)
+		g.fieldTarget = g.player.position
+	}
+	return g.field
+}
+
+// eatPill turns every ghost blue for the configured pill duration
+func (g *Game) eatPill() {
+	g.stats.Pills++
+	g.player.score += 10
+	g.publish(EventPillEaten, "")
+	g.frightened = g.rules.PillDurationSecs * g.rules.FrameRate
+	g.setGhostStatus(GhostStatusBlue)
+}
+
+func (g *Game) setGhostStatus(status GhostStatus) {
+	for _, s := range g.sprites {
+		switch ghost := s.(type) {
+		case *Ghost:
+			ghost.status = status
+		case *Chaser:
+			ghost.status = status
+		}
+	}
+}
+
+// collisions resolves every ghost sharing a tile with the player, until
+// the player runs out of lives: a player who died stays where they were, so
+// a second ghost on the same tile would otherwise kill them again
+func (g *Game) collisions() {
+	for _, s := range g.sprites {
+		if g.Over() {
+			return
+		}
+		switch ghost := s.(type) {
+		case *Ghost:
+			g.collide(ghost)
+		case *Chaser:
+			g.collide(&ghost.Ghost)
+		}
+	}
+}
+
+// collide resolves a ghost and the player sharing a tile: a blue ghost is
+// eaten and sent home, otherwise the player dies
+func (g *Game) collide(ghost *Ghost) {
+	if ghost.position != g.player.position {
+		return
+	}
+
+	if ghost.status == GhostStatusBlue {
+		g.stats.GhostsEaten++
+		ghost.status = GhostStatusNormal
+		ghost.position = ghost.origin
+		g.publish(EventGhostEaten, ghost.kind)
+	} else {
+		g.stats.Deaths++
+		g.stats.DeathsBy[ghost.kind]++
+		g.player.Kill()
+		g.publish(EventDeath, ghost.kind)
+	}
+}
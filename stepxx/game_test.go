@@ -0,0 +1,106 @@
+package main
+
+import (
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stressKeys are the key presses sent to the games under stress, including
+// ESC to open the pause menu and restart from there
+var stressKeys = []string{"\x1b[A", "\x1b[B", "\x1b[C", "\x1b[D", "\x1b", "p", "d", "x", "\r"}
+
+// stressSpeed goes to the options in the pause menu, changes the speed and
+// goes back to the game. Random keys rarely get that far on their own.
+var stressSpeed = []string{"\x1b", "\x1b[B", "\x1b[B", "\r", "\x1b[D", "\x1b[C", "\x1b[D", "\x1b", "\x1b"}
+
+// TestGameLoopStress plays many games at once at a very high frame rate,
+// fed random key presses through a pipe, with spectators watching. Run it
+// with -race to check that each game is only ever touched by its own loop.
+func TestGameLoopStress(t *testing.T) {
+	if err := loadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	cfg.FrameRate = 1000
+	maze := loadTestMaze(t)
+
+	oldOutput, oldSpectators := output, spectators
+	defer func() { output, spectators = oldOutput, oldSpectators }()
+	output = io.Discard
+	oldLog := log.Writer()
+	defer log.SetOutput(oldLog)
+	log.SetOutput(io.Discard)
+
+	var err error
+	spectators, err = ListenSpectators("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spectators.Close()
+	for i := 0; i < 3; i++ {
+		conn, err := net.Dial("tcp", spectators.ln.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		go io.Copy(io.Discard, conn)
+	}
+
+	games, keys := 16, 300
+	if testing.Short() {
+		games, keys = 4, 100
+	}
+
+	var wg sync.WaitGroup
+	var ticks atomic.Int64
+	for i := 0; i < games; i++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+
+			r, w := io.Pipe()
+			go func() {
+				rng := rand.New(rand.NewSource(seed))
+				for k := 0; k < keys; k++ {
+					presses := []string{stressKeys[rng.Intn(len(stressKeys))]}
+					if rng.Intn(20) == 0 {
+						presses = stressSpeed
+					}
+					for _, key := range presses {
+						if _, err := io.WriteString(w, key); err != nil {
+							return
+						}
+						time.Sleep(time.Duration(rng.Intn(2000)) * time.Microsecond)
+					}
+				}
+				w.Close()
+			}()
+			input := readInputs(r)
+
+			var bot Controller
+			if seed%2 == 0 {
+				bot, _ = NewController("greedy", seed)
+			}
+			// a game restarted from the pause menu just before the keys
+			// run out may end without a single tick, so only the total is
+			// checked
+			game, _ := gameLoop(maze, input, bot, NewAchievements(nil), nil)
+			ticks.Add(int64(game.stats.Ticks))
+
+			// the game may end before the keys run out
+			r.Close()
+			for range input {
+			}
+		}(int64(i))
+	}
+	wg.Wait()
+
+	if ticks.Load() == 0 {
+		t.Error("no game ever advanced")
+	}
+}
@@ -2,17 +2,30 @@ package main
 
 import "math/rand"
 
+type GhostStatus string
+
+const (
+	GhostStatusNormal GhostStatus = "Normal"
+	GhostStatusBlue   GhostStatus = "Blue"
+)
+
 // Ghost is the enemy that chases the player :O
 type Ghost struct {
 	position Point
+	origin   Point
+	kind     string
 	img      string
+	status   GhostStatus
 }
 
 // NewGhost creates a new ghost
 func NewGhost(row, col int, img string) *Ghost {
 	var g Ghost
 	g.position = Point{row, col}
+	g.origin = Point{row, col}
+	g.kind = "ghost"
 	g.img = img
+	g.status = GhostStatusNormal
 	return &g
 }
 
@@ -23,25 +36,19 @@ func (g *Ghost) Pos() (row, col int) {
 }
 
 func (g *Ghost) Img() string {
+	if g.status == GhostStatusBlue {
+		return cfg.GhostBlue
+	}
 	return g.img
 }
 
-func (g *Ghost) Move() {
-	dir := g.drawDirection()
-	g.position = makeMove(g.position, dir)
-
-	for _, s := range sprites {
-		switch p := s.(type) {
-		case *Player:
-			if p.position == g.position {
-				p.Kill()
-			}
-		}
-	}
+func (g *Ghost) Move(game *Game) {
+	dir := g.drawDirection(game.rng)
+	g.position = makeMove(game.maze, g.position, dir)
 }
 
-func (g *Ghost) drawDirection() string {
-	dir := rand.Intn(4)
+func (g *Ghost) drawDirection(rng *rand.Rand) string {
+	dir := rng.Intn(4)
 	move := map[int]string{
 		0: "UP",
 		1: "DOWN",
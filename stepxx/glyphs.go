@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// glyphWidths are how wide the terminal draws each glyph measured at
+// startup, kept to align the glyphs again when the configuration is
+// reloaded
+var glyphWidths = make(map[string]int)
+
+// boxGlyph stands for every box-drawing character when measuring widths
+const boxGlyph = "═"
+
+// alignGlyphs measures how wide the terminal really draws the configured
+// glyphs, since emoji widths vary between terminals and fonts, and makes
+// every tile exactly as wide as moveCursor expects. It must run in cbreak
+// mode, before anything else reads from stdin.
+func alignGlyphs() []string {
+	if !cfg.UseEmoji && cfg.WallStyle != wallBox {
+		return nil
+	}
+
+	// let reads give up after half a second, in case the terminal doesn't
+	// answer cursor position reports
+	setReadTimeout("5")
+	defer setReadTimeout("0")
+	defer fmt.Print("\r\x1b[2K")
+
+	measure := []string{boxGlyph}
+	if cfg.UseEmoji {
+		for _, g := range cfg.glyphs() {
+			measure = append(measure, *g.glyph)
+		}
+	}
+
+	r := bufio.NewReader(os.Stdin)
+	for _, glyph := range measure {
+		width, ok := measureWidth(r, glyph)
+		if !ok {
+			return []string{"The terminal didn't report glyph widths, emoji and walls may be misaligned"}
+		}
+		glyphWidths[glyph] = width
+	}
+	return fitGlyphs()
+}
+
+// fitGlyphs uses the measured widths to make every glyph fit its tile.
+// Glyphs that are too narrow are padded with spaces, glyphs that can't be
+// made to fit are replaced, and a warning is returned for each
+// replacement. Glyphs that were never measured are left as they are.
+func fitGlyphs() []string {
+	var warnings []string
+
+	if cfg.UseEmoji {
+		for _, g := range cfg.glyphs() {
+			width, ok := glyphWidths[*g.glyph]
+			if !ok {
+				continue
+			}
+
+			fitted, ok := fitGlyph(*g.glyph, width)
+			if !ok {
+				fitted = g.fallback + " "
+				warnings = append(warnings, fmt.Sprintf("%s %+q is %d columns wide, using %q", g.name, *g.glyph, width, g.fallback))
+			}
+			*g.glyph = fitted
+		}
+	}
+
+	if width, ok := glyphWidths[boxGlyph]; ok && width != 1 && cfg.WallStyle == wallBox {
+		cfg.WallStyle = wallSolid
+		warnings = append(warnings, "Box-drawing characters are too wide, using solid walls")
+	}
+	return warnings
+}
+
+// fitGlyph pads glyph to the two columns of an emoji tile, given that the
+// terminal draws it width columns wide. Trailing spaces added in the
+// configuration to make up for narrow glyphs are dropped first, since the
+// terminal may already draw the glyph two columns wide.
+func fitGlyph(glyph string, width int) (string, bool) {
+	trimmed := strings.TrimRight(glyph, " ")
+	width -= len(glyph) - len(trimmed)
+	if trimmed == "" {
+		return "  ", true
+	}
+	if width < 1 || width > 2 {
+		return "", false
+	}
+	return trimmed + strings.Repeat(" ", 2-width), true
+}
+
+// measureWidth draws text at the start of the line and asks the terminal
+// where the cursor ended up. It returns false if there is no answer.
+func measureWidth(r *bufio.Reader, text string) (int, bool) {
+	fmt.Print("\r\x1b[2K" + text + "\x1b[6n")
+
+	// the answer is ESC [ row ; col R
+	answer, err := r.ReadString('R')
+	if err != nil {
+		return 0, false
+	}
+	sep := strings.LastIndexByte(answer, ';')
+	if sep < 0 {
+		return 0, false
+	}
+	col, err := strconv.Atoi(answer[sep+1 : len(answer)-1])
+	if err != nil {
+		return 0, false
+	}
+	return col - 1, true
+}
+
+// setReadTimeout sets how long reads from the terminal wait for input, in
+// tenths of a second, with "0" meaning forever
+func setReadTimeout(tenths string) {
+	minChars := "0"
+	if tenths == "0" {
+		minChars = "1"
+	}
+	cmd := exec.Command("stty", "min", minChars, "time", tenths)
+	cmd.Stdin = os.Stdin
+	cmd.Run()
+}
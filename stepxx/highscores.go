@@ -0,0 +1,136 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxHighScores is how many scores are kept for each maze and rule set
+const maxHighScores = 10
+
+// HighScore is an entry in the high score table
+type HighScore struct {
+	Name  string    `json:"name"`
+	Score int       `json:"score"`
+	Date  time.Time `json:"date"`
+}
+
+// HighScoreStore keeps the high score tables in a JSON file, one table per
+// maze and rule set. Several games can share the same file: updates are
+// serialised with a lock file and written atomically.
+type HighScoreStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewHighScoreStore uses the file at path, or the default location in the
+// XDG data directory if path is empty
+func NewHighScoreStore(path string) (*HighScoreStore, error) {
+	path, err := dataFile(path, "highscores.json")
+	if err != nil {
+		return nil, err
+	}
+	return &HighScoreStore{path: path}, nil
+}
+
+// scoreKey identifies a high score table by the contents of the maze and
+// the rules that affect how hard it is to score. A finished game's score
+// goes in the table for the rules it started with, not whatever the
+// configuration was changed to while it was played.
+func scoreKey(maze Maze, c Config) string {
+	rules := fmt.Sprintf("fps=%d,pill=%ds,lives=%d/%d,ghosts=%s@%d%%,extra=%d+%d",
+		c.FrameRate, c.PillDurationSecs, c.Lives, c.MaxLives, c.Ghosts, c.GhostSpeed, c.ExtraLifeScore, c.ExtraLifeEvery)
+	return mazeID(maze) + "/" + rules
+}
+
+// mazeID identifies a maze by its contents, whatever file it was loaded
+// from
+func mazeID(maze Maze) string {
+	sum := sha256.Sum256([]byte(strings.Join(maze, "\n")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Scores returns the table for key, best first
+func (s *HighScoreStore) Scores(key string) ([]HighScore, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tables, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return tables[key], nil
+}
+
+// Add records score in the table for key and returns the updated table
+func (s *HighScoreStore) Add(key string, score HighScore) ([]HighScore, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := lockFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// read again under the lock, another game may have finished since
+	tables, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	table := append(tables[key], score)
+	sort.SliceStable(table, func(i, j int) bool {
+		return table[i].Score > table[j].Score
+	})
+	if len(table) > maxHighScores {
+		table = table[:maxHighScores]
+	}
+	tables[key] = table
+
+	return table, s.write(tables)
+}
+
+// qualifies reports whether score would make it into table
+func qualifies(table []HighScore, score int) bool {
+	if score <= 0 {
+		return false
+	}
+	return len(table) < maxHighScores || score > table[len(table)-1].Score
+}
+
+func (s *HighScoreStore) read() (map[string][]HighScore, error) {
+	tables := make(map[string][]HighScore)
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return tables, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &tables)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", s.path, err)
+	}
+	return tables, nil
+}
+
+// write replaces the file in one go, so readers never see half a table
+func (s *HighScoreStore) write(tables map[string][]HighScore) error {
+	data, err := json.MarshalIndent(tables, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, data)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScoreKey(t *testing.T) {
+	maze := Maze{"#####", "#P.G#", "#####"}
+	rules := defaultConfig
+	rules.FrameRate = 5
+	key := scoreKey(maze, rules)
+
+	faster := rules
+	faster.FrameRate = 30
+	fewerLives := rules
+	fewerLives.Lives = 1
+	restyled := rules
+	restyled.Player = "@"
+	restyled.Theme = "monochrome"
+
+	tests := []struct {
+		name string
+		maze Maze
+		c    Config
+		same bool
+	}{
+		{"same maze and rules", Maze{"#####", "#P.G#", "#####"}, rules, true},
+		{"only the look changed", maze, restyled, true},
+		{"other maze", Maze{"#####", "#PG.#", "#####"}, rules, false},
+		{"other frame rate", maze, faster, false},
+		{"other lives", maze, fewerLives, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scoreKey(tt.maze, tt.c)
+			if (got == key) != tt.same {
+				t.Errorf("scoreKey = %q, compared with %q: same = %v, want %v", got, key, got == key, tt.same)
+			}
+		})
+	}
+
+	if !strings.HasPrefix(key, mazeID(maze)+"/") {
+		t.Errorf("scoreKey = %q, want it to start with the maze ID %q", key, mazeID(maze))
+	}
+
+	// a game's score goes under the rules it started with, even if the
+	// configuration changes while it is played
+	old := cfg
+	defer func() { cfg = old }()
+	cfg = rules
+	g := NewGame(maze, 1)
+	cfg.FrameRate = 30
+	if got := scoreKey(maze, g.rules); got != key {
+		t.Errorf("scoreKey of the game's rules = %q after changing the frame rate, want %q", got, key)
+	}
+}
+
+func TestQualifies(t *testing.T) {
+	full := make([]HighScore, maxHighScores)
+	for i := range full {
+		full[i].Score = 100 * (maxHighScores - i)
+	}
+	lowest := full[len(full)-1].Score
+
+	tests := []struct {
+		name  string
+		table []HighScore
+		score int
+		want  bool
+	}{
+		{"empty table", nil, 1, true},
+		{"nothing scored", nil, 0, false},
+		{"room left", full[:3], 1, true},
+		{"beats the lowest", full, lowest + 1, true},
+		{"ties the lowest", full, lowest, false},
+		{"below the lowest", full, lowest - 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := qualifies(tt.table, tt.score); got != tt.want {
+				t.Errorf("qualifies(%d scores, %d) = %v, want %v", len(tt.table), tt.score, got, tt.want)
+			}
+		})
+	}
+}
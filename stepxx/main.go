@@ -2,129 +2,155 @@ package main
 
 import (
 	"bufio"
-	"encoding/json"
+	"bytes"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strings"
 	"time"
-
-	"github.com/danicat/simpleansi"
 )
 
 var (
-	configFile = flag.String("config-file", "config.json", "path to custom configuration file")
-	mazeFile   = flag.String("maze-file", "maze01.txt", "path to a custom maze file")
+	configFile   = flag.String("config-file", "", "path to custom configuration file (default built-in config.json)")
+	configSets   = configFlag{}
+	difficulty   = flag.String("difficulty", "", "easy, normal, hard or nightmare (default from the configuration, normal if not set)")
+	mazeFile     = flag.String("maze-file", "", "path to a custom maze file (default built-in maze01.txt)")
+	controller   = flag.String("player-controller", "", "name of a bot to play instead of the keyboard, e.g. greedy")
+	spectate     = flag.String("spectate-addr", "", "address to accept read-only spectators on, e.g. :7777 (disabled if empty)")
+	profile      = flag.String("profile", "default", "name of the profile to record lifetime statistics under")
+	profileFile  = flag.String("profile-file", "", "path to the profiles file (default $XDG_DATA_HOME/pacgo/profiles.json)")
+	highScores   = flag.String("highscore-file", "", "path to the high score file (default $XDG_DATA_HOME/pacgo/highscores.json)")
+	recordCast   = flag.String("record-cast", "", "record the session to this file in asciicast v2 format, e.g. out.cast")
+	recordReplay = flag.String("record-replay", "", "save a replay of the last game played to this file, e.g. game.rep")
+	debug        = flag.Bool("debug", false, "start with the debug overlay showing what the ghosts are doing (toggle with D)")
 )
 
-var player *Player
-var sprites []Sprite
-
-// Config holds the emoji configuration
-type Config struct {
-	Player    string        `json:"player"`
-	Ghost     string        `json:"ghost"`
-	Wall      string        `json:"wall"`
-	Dot       string        `json:"dot"`
-	Pill      string        `json:"pill"`
-	Death     string        `json:"death"`
-	Space     string        `json:"space"`
-	Chaser    string        `json:"chaser"`
-	UseEmoji  bool          `json:"use_emoji"`
-	FrameRate time.Duration `json:"frame_rate"`
+func init() {
+	flag.Var(&configSets, "set", "override a configuration setting, e.g. -set frame_rate=10 (can be repeated)")
 }
 
-var cfg Config
+var spectators *Spectators
 
-func loadConfig() error {
-	f, err := os.Open(*configFile)
+// loadMaze reads the maze at path, or the built-in maze if path is empty
+func loadMaze(path string) (Maze, error) {
+	f, err := openAsset(path, defaultMazeFile)
 	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	decoder := json.NewDecoder(f)
-	err = decoder.Decode(&cfg)
-	if err != nil {
-		return err
-	}
-
-	// Default: 5 FPS
-	if cfg.FrameRate == 0 {
-		cfg.FrameRate = 5
-	}
-
-	return nil
-}
-
-func loadMaze() error {
-	f, err := os.Open(*mazeFile)
-	if err != nil {
-		return err
+		return nil, err
 	}
 	defer f.Close()
 
+	var maze Maze
 	scanner := bufio.NewScanner(f)
 	for scanner.Scan() {
 		line := scanner.Text()
 		maze = append(maze, line)
 	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
 
-	for row, line := range maze {
-		for col, char := range line {
-			switch char {
-			case 'P':
-				player = NewPlayer(row, col, 1, cfg.Player)
-				sprites = append(sprites, player)
-			case 'G':
-				sprites = append(sprites, NewGhost(row, col, cfg.Ghost))
-			case 'C':
-				sprites = append(sprites, NewChaser(row, col, cfg.Chaser))
-			case '.':
-				numDots++
-			}
+	for _, line := range maze {
+		if strings.ContainsRune(line, 'P') {
+			return maze, nil
 		}
 	}
-
-	return nil
+	return nil, errors.New("maze has no player starting position")
 }
 
-var maze []string
-var numDots int
+// printScreen draws the part of the maze around the player that fits on
+// the terminal, and returns it so overlays can be placed on top
+func printScreen(w io.Writer, g *Game) viewport {
+	clearScreen(w)
+	v := newViewport(g.maze, g.player.position)
+	if v.tooSmall() {
+		printTooSmall(w, v)
+		return v
+	}
 
-func printScreen() {
-	simpleansi.ClearScreen()
-	for _, line := range maze {
-		for _, chr := range line {
+	var walls WallTiles
+	if cfg.WallStyle == wallBox {
+		walls = g.wallTiles()
+	}
+
+	for row := v.top; row < v.top+v.rows; row++ {
+		line := g.maze[row]
+		for col := v.left; col < v.left+v.cols; col++ {
+			chr := byte(' ')
+			if col < len(line) {
+				chr = line[col]
+			}
 			switch chr {
 			case '#':
-				fmt.Print(simpleansi.WithBlueBackground(cfg.Wall))
+				if walls != nil {
+					fmt.Fprint(w, theme.Wall.paint(walls[row][col]))
+				} else {
+					fmt.Fprint(w, theme.Wall.solid().paint(cfg.Wall))
+				}
 			case '.':
-				fmt.Print(cfg.Dot)
+				fmt.Fprint(w, theme.Dot.paint(cfg.Dot))
 			case 'X':
-				fmt.Print(cfg.Pill)
+				fmt.Fprint(w, theme.Pill.paint(cfg.Pill))
 			default:
-				fmt.Print(cfg.Space)
+				fmt.Fprint(w, cfg.Space)
 			}
 		}
-		fmt.Println()
+		fmt.Fprintln(w)
 	}
 
-	for _, s := range sprites {
-		moveCursor(s.Pos())
-		fmt.Print(s.Img())
+	if pos, ok := g.FruitPos(); ok && v.contains(pos) {
+		v.moveCursor(w, pos)
+		fmt.Fprint(w, theme.Fruit.paint(cfg.Fruit))
+	}
+
+	for _, s := range g.sprites {
+		row, col := s.Pos()
+		if p := (Point{row, col}); v.contains(p) {
+			v.moveCursor(w, p)
+			fmt.Fprint(w, spriteStyle(s).paint(s.Img()))
+		}
 	}
 
-	moveCursor(len(maze)+1, 0)
-	fmt.Println("Score:", player.score, "\tLives:", player.lives)
+	moveCursor(w, v.hudRow(), 0)
+	fmt.Fprintln(w, theme.HUD.paint(statusBar(g)))
+	return v
+}
+
+// commands are the alternatives to playing in the terminal, selected by
+// the first argument after the flags
+var commands = map[string]func(args []string) error{
+	"assets":     runAssets,
+	"config":     runConfig,
+	"env":        runEnv,
+	"render":     runRender,
+	"screenshot": runScreenshot,
+	"stats":      runStats,
+	"tournament": runTournament,
+	"web":        runWeb,
 }
 
 func main() {
 	flag.Parse()
 
+	if flag.NArg() > 0 {
+		cmd, ok := commands[flag.Arg(0)]
+		if !ok {
+			log.Println("Unknown command:", flag.Arg(0))
+			return
+		}
+		err := cmd(flag.Args()[1:])
+		if err != nil {
+			log.Println("Error running", flag.Arg(0)+":", err)
+		}
+		return
+	}
+
 	// initialise game
 	initialise()
 	defer cleanup()
+	watchTerminalSize()
 
 	// load resources
 	err := loadConfig()
@@ -133,33 +159,251 @@ func main() {
 		return
 	}
 
-	err = loadMaze()
+	warnings := alignGlyphs()
+
+	maze, err := loadMaze(*mazeFile)
 	if err != nil {
 		log.Println("Error loading maze:", err)
 		return
 	}
 
-	// game loop
+	var bot Controller
+	if *controller != "" {
+		bot, err = NewController(*controller, time.Now().UnixNano())
+		if err != nil {
+			log.Println("Error loading player controller:", err)
+			return
+		}
+	}
+
+	if *spectate != "" {
+		spectators, err = ListenSpectators(*spectate)
+		if err != nil {
+			log.Println("Error starting spectator server:", err)
+			return
+		}
+		defer spectators.Close()
+	}
+
+	if *recordCast != "" {
+		cast, err := NewCastRecorder(*recordCast, output)
+		if err != nil {
+			log.Println("Error starting recording:", err)
+			return
+		}
+		output = cast
+		defer func() {
+			if err := cast.Close(); err != nil {
+				log.Println("Error saving recording:", err)
+			}
+		}()
+	}
+
+	// process input (async)
+	input := readInputs(os.Stdin)
+
+	store, err := NewHighScoreStore(*highScores)
+	if err != nil {
+		log.Println("Error opening high scores:", err)
+		return
+	}
+
+	profiles, err := NewProfileStore(*profileFile)
+	if err != nil {
+		log.Println("Error opening profiles:", err)
+		return
+	}
+
+	var unlocked map[string]time.Time
+	if all, err := profiles.Profiles(); err == nil && all[*profile] != nil {
+		unlocked = all[*profile].Achievements
+	}
+	tracker := NewAchievements(unlocked)
+	watcher := newConfigWatcher(*configFile)
+
+	for titleScreen(maze, store, warnings, input) {
+		for {
+			// reload the maze so edits show up without relaunching
+			maze, err = loadMaze(*mazeFile)
+			if err != nil {
+				log.Println("Error loading maze:", err)
+				return
+			}
+
+			game, quit := gameLoop(maze, input, bot, tracker, watcher)
+			if *recordReplay != "" {
+				if err := NewReplay(game).Save(*recordReplay); err != nil {
+					log.Println("Error saving replay:", err)
+				}
+			}
+			err = profiles.Update(*profile, func(p *Profile) {
+				p.Record(mazeName(), game)
+				p.Unlock(tracker.Unlocked())
+			})
+			if err != nil {
+				log.Println("Error saving profile:", err)
+			}
+			if quit {
+				return
+			}
+
+			recordHighScore(store, scoreKey(maze, game.rules), game, input)
+			if !gameOverScreen(game, input) {
+				break
+			}
+		}
+	}
+}
+
+// recordHighScore asks for the player's name if their score made it into
+// the high score table under key, and saves it
+func recordHighScore(store *HighScoreStore, key string, game *Game, input <-chan string) {
+	scores, err := store.Scores(key)
+	if err != nil || !qualifies(scores, game.player.score) {
+		return
+	}
+
+	name, ok := nameEntryScreen(game, input)
+	if !ok {
+		return
+	}
+
+	_, err = store.Add(key, HighScore{Name: name, Score: game.player.score, Date: time.Now()})
+	if err != nil {
+		log.Println("Error saving high score:", err)
+	}
+}
+
+// gameLoop plays a game on maze until it is over or the player quits, and
+// returns the final state of the game. It is the only goroutine touching
+// the game: key presses arrive on input, and everything else happens in
+// order once per tick, including applying changes to the configuration file
+// noticed by watcher.
+func gameLoop(maze Maze, input <-chan string, bot Controller, tracker *Achievements, watcher *configWatcher) (game *Game, quit bool) {
+	var hud notices
+	tracker.OnUnlock = func(a Achievement) {
+		hud.add("Achievement unlocked: " + a.Name + " - " + a.Description)
+	}
+
+	// ring the terminal bell along with the notice of an extra life
+	bell := false
+
+	newGame := func() *Game {
+		g := NewGame(withDifficultyGhosts(maze), time.Now().UnixNano())
+		tracker.Watch(g, maze)
+		g.events.Subscribe(func(e Event) {
+			if e.Type == EventExtraLife {
+				hud.add("Extra life!")
+				bell = true
+			}
+		})
+		return g
+	}
+
+	game = newGame()
+	paused := false
+
+	// the speed chosen in the pause menu lasts until the loop returns,
+	// without touching the configuration other games start from
+	frameRate := cfg.FrameRate
+	showDebug := *debug
+	var menu *pauseMenu
+
+	var frame bytes.Buffer
 	for {
-		// process movement
-		for _, s := range sprites {
-			go s.Move()
+		// process input
+		var inp string
+		select {
+		case key, ok := <-input:
+			inp = key
+			if !ok {
+				inp = "QUIT"
+			}
+		default:
+		}
+
+		// the debug overlay can be toggled at any time, without using up the
+		// game's input for this tick
+		if menu == nil && (inp == "d" || inp == "D") {
+			showDebug = !showDebug
+			inp = ""
+		}
+
+		switch {
+		case inp == "QUIT":
+			return game, true
+		case menu != nil:
+			switch menu.handle(inp) {
+			case menuResume:
+				menu = nil
+			case menuRestart:
+				game = newGame()
+				menu = nil
+			case menuQuitNow:
+				moveCursor(&frame, newViewport(game.maze, game.player.position).hudRow()+1, 0)
+				flushFrame(&frame)
+				return game, true
+			}
+		case inp == "ESC":
+			menu = newPauseMenu(&frameRate)
+		case inp == "p" || inp == "P":
+			paused = !paused
+		case paused:
+			// nothing moves, including the pill timer
+		default:
+			if bot != nil {
+				inp = bot.Direction(View{game})
+			}
+
+			// process movement
+			game.Update(inp)
+		}
+
+		if watcher.changed() {
+			warnings, err := reloadConfig(game)
+			if err != nil {
+				hud.add("Configuration not reloaded: " + err.Error())
+			} else {
+				hud.add("Configuration reloaded")
+				frameRate = cfg.FrameRate
+			}
+			for _, warning := range warnings {
+				hud.add("Warning: " + warning)
+			}
 		}
 
 		// update screen
-		printScreen()
+		v := printScreen(&frame, game)
+		if showDebug {
+			drawDebug(&frame, game, v)
+		}
+		if bell {
+			fmt.Fprint(&frame, "\a")
+			bell = false
+		}
+		hud.draw(&frame, v)
+		if menu != nil {
+			menu.draw(&frame, v)
+		} else if paused {
+			drawBox(&frame, v, []string{"PAUSED", "", "Press P to resume"}, -1)
+		}
 
 		// check game over
-		if numDots == 0 || player.lives <= 0 {
-			if player.lives <= 0 {
-				moveCursor(player.Pos())
-				fmt.Print(cfg.Death)
-				moveCursor(len(maze)+2, 0)
+		if game.Over() {
+			if game.player.lives <= 0 && !v.tooSmall() {
+				v.moveCursor(&frame, game.player.position)
+				fmt.Fprint(&frame, cfg.Death)
+				moveCursor(&frame, v.hudRow()+1, 0)
 			}
-			break
+			flushFrame(&frame)
+
+			// dramatic pause before the game over screen
+			time.Sleep(time.Second)
+			return game, false
 		}
+		flushFrame(&frame)
 
 		// wait before rendering next frame
-		time.Sleep(1000 / cfg.FrameRate * time.Millisecond)
+		time.Sleep(time.Second / time.Duration(frameRate))
 	}
 }
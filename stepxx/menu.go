@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// Menu screens
+const (
+	menuMain    = "main"
+	menuOptions = "options"
+	menuQuit    = "quit"
+)
+
+// Menu actions the game loop has to carry out
+const (
+	menuResume  = "resume"
+	menuRestart = "restart"
+	menuQuitNow = "quit"
+)
+
+// pauseMenu is the in-game menu shown over a paused game. It is navigated
+// with the arrow keys and Enter; ESC goes back one screen.
+type pauseMenu struct {
+	screen   string
+	selected int
+
+	// speed is the frame rate of the game loop the menu belongs to,
+	// changed from the options screen
+	speed *int
+}
+
+func newPauseMenu(speed *int) *pauseMenu {
+	return &pauseMenu{screen: menuMain, speed: speed}
+}
+
+func (m *pauseMenu) title() string {
+	switch m.screen {
+	case menuOptions:
+		return "OPTIONS"
+	case menuQuit:
+		return "QUIT GAME?"
+	default:
+		return "PAUSED"
+	}
+}
+
+func (m *pauseMenu) items() []string {
+	switch m.screen {
+	case menuOptions:
+		return []string{
+			fmt.Sprintf("Speed: < %d fps >", *m.speed),
+			"Back",
+		}
+	case menuQuit:
+		// the safe choice comes first, so an accidental Enter doesn't
+		// end the run
+		return []string{"No, keep playing", "Yes, quit"}
+	default:
+		return []string{"Resume", "Restart level", "Options", "Quit"}
+	}
+}
+
+func (m *pauseMenu) open(screen string) {
+	m.screen = screen
+	m.selected = 0
+}
+
+// handle processes a key press and returns the action the game loop must
+// take, if any
+func (m *pauseMenu) handle(input string) string {
+	switch input {
+	case "UP":
+		m.selected = (m.selected + len(m.items()) - 1) % len(m.items())
+	case "DOWN":
+		m.selected = (m.selected + 1) % len(m.items())
+	case "LEFT", "RIGHT":
+		if m.screen == menuOptions && m.selected == 0 {
+			m.changeSpeed(input)
+		}
+	case "ESC":
+		if m.screen == menuMain {
+			return menuResume
+		}
+		m.open(menuMain)
+	case "ENTER":
+		return m.choose()
+	}
+	return ""
+}
+
+func (m *pauseMenu) choose() string {
+	switch m.screen {
+	case menuOptions:
+		if m.selected == 1 {
+			m.open(menuMain)
+		}
+	case menuQuit:
+		if m.selected == 1 {
+			return menuQuitNow
+		}
+		return menuResume
+	default:
+		switch m.selected {
+		case 0:
+			return menuResume
+		case 1:
+			return menuRestart
+		case 2:
+			m.open(menuOptions)
+		case 3:
+			m.open(menuQuit)
+		}
+	}
+	return ""
+}
+
+func (m *pauseMenu) changeSpeed(dir string) {
+	if dir == "LEFT" && *m.speed > 1 {
+		*m.speed--
+	}
+	if dir == "RIGHT" && *m.speed < maxFrameRate {
+		*m.speed++
+	}
+}
+
+// draw renders the menu over the maze
+func (m *pauseMenu) draw(w io.Writer, v viewport) {
+	lines := append([]string{m.title(), ""}, m.items()...)
+	drawBox(w, v, lines, m.selected+2)
+}
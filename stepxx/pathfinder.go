@@ -0,0 +1,196 @@
+package main
+
+import "container/heap"
+
+// PathFinder implements the A* pathfinding algorithm
+//
+// move cost = 1
+// no diagonal movement allowed
+// heuristic = manhattan distance, allowing for the tunnels that wrap around
+// the edges of the maze, or the exact distance when a cache is available
+type PathFinder struct {
+	maze      Maze
+	distances *DistanceCache
+}
+
+func abs(n int) int {
+	y := n >> 63
+	return (n ^ y) - y
+}
+
+func distance(p1, p2 Point) int {
+	return abs(p1.row-p2.row) + abs(p1.col-p2.col)
+}
+
+// wrapDistance is the manhattan distance between two points when walking
+// off one edge of the maze leads back in on the opposite edge
+func wrapDistance(maze Maze, p1, p2 Point) int {
+	rows := abs(p1.row - p2.row)
+	rows = min(rows, len(maze)-rows)
+	cols := abs(p1.col - p2.col)
+	cols = min(cols, maze.Width()-cols)
+	return rows + cols
+}
+
+func (pf *PathFinder) heuristic(p, target Point) int {
+	if pf.distances != nil {
+		if d := pf.distances.Distance(p, target); d >= 0 {
+			return d
+		}
+	}
+	return wrapDistance(pf.maze, p, target)
+}
+
+// walk returns a shortest path from start to target, excluding start and
+// including target. The path is empty if start is the target and nil if
+// target can't be reached.
+func (pf *PathFinder) walk(start Point, target Point) []Point {
+	if start == target {
+		return []Point{}
+	}
+
+	parent := map[Point]Point{}
+	cost := map[Point]int{start: 0}
+	closed := map[Point]bool{}
+
+	var open openSet
+	heap.Push(&open, &node{Point: start, h: pf.heuristic(start, target)})
+
+	for open.Len() > 0 {
+		current := heap.Pop(&open).(*node)
+		if current.Point == target {
+			var path []Point
+			for p := target; p != start; p = parent[p] {
+				path = append(path, p)
+			}
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path
+		}
+		if closed[current.Point] {
+			// stale entry, a cheaper route to this point was found after
+			// it was pushed
+			continue
+		}
+		closed[current.Point] = true
+
+		for _, dir := range directions {
+			n := makeMove(pf.maze, current.Point, dir)
+			if n == current.Point || closed[n] {
+				continue
+			}
+
+			g := current.g + 1
+			if old, ok := cost[n]; ok && old <= g {
+				continue
+			}
+			cost[n] = g
+			parent[n] = current.Point
+			open.seq++
+			heap.Push(&open, &node{Point: n, g: g, h: pf.heuristic(n, target), seq: open.seq})
+		}
+	}
+	return nil
+}
+
+// node is a point waiting in the open set
+type node struct {
+	Point
+	g   int // cost from the start
+	h   int // estimated cost to the target
+	seq int // insertion order, so ties always break the same way
+}
+
+// openSet is a priority queue of nodes ordered by estimated total cost
+type openSet struct {
+	nodes []*node
+	seq   int
+}
+
+func (s openSet) Len() int { return len(s.nodes) }
+
+func (s openSet) Less(i, j int) bool {
+	a, b := s.nodes[i], s.nodes[j]
+	if a.g+a.h != b.g+b.h {
+		return a.g+a.h < b.g+b.h
+	}
+	if a.h != b.h {
+		return a.h < b.h
+	}
+	return a.seq < b.seq
+}
+
+func (s openSet) Swap(i, j int) { s.nodes[i], s.nodes[j] = s.nodes[j], s.nodes[i] }
+
+func (s *openSet) Push(x any) { s.nodes = append(s.nodes, x.(*node)) }
+
+func (s *openSet) Pop() any {
+	n := s.nodes[len(s.nodes)-1]
+	s.nodes = s.nodes[:len(s.nodes)-1]
+	return n
+}
+
+// DistanceCache remembers the length of the shortest path from every tile
+// of a maze to the targets asked for so far. Walls never move during a
+// game, so each target only needs one breadth first search.
+type DistanceCache struct {
+	maze    Maze
+	targets map[Point][][]int
+}
+
+// NewDistanceCache creates an empty cache for the walls of maze
+func NewDistanceCache(maze Maze) *DistanceCache {
+	return &DistanceCache{
+		maze:    maze,
+		targets: make(map[Point][][]int),
+	}
+}
+
+// Distance returns the number of moves needed to get from p to target, or
+// -1 if target can't be reached from p
+func (dc *DistanceCache) Distance(p, target Point) int {
+	return dc.Field(target)[p.row][p.col]
+}
+
+// Field returns the distance from every tile to target, indexed by row and
+// column. Tiles that can't reach target are -1. Following decreasing
+// distances from any tile leads to target along a shortest path.
+func (dc *DistanceCache) Field(target Point) [][]int {
+	dist, ok := dc.targets[target]
+	if !ok {
+		dist = dc.search(target)
+		dc.targets[target] = dist
+	}
+	return dist
+}
+
+// search fills a distance map with a breadth first search from target.
+// Every move can be reversed, so the distance from target to p is also the
+// distance from p to target. Every row is as wide as the widest one, so
+// tiles past the end of a shorter row are simply unreachable.
+func (dc *DistanceCache) search(target Point) [][]int {
+	width := dc.maze.Width()
+	dist := make([][]int, len(dc.maze))
+	for row := range dist {
+		dist[row] = make([]int, width)
+		for col := range dist[row] {
+			dist[row][col] = -1
+		}
+	}
+
+	dist[target.row][target.col] = 0
+	queue := []Point{target}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, dir := range directions {
+			n := makeMove(dc.maze, p, dir)
+			if n != p && dist[n.row][n.col] < 0 {
+				dist[n.row][n.col] = dist[p.row][p.col] + 1
+				queue = append(queue, n)
+			}
+		}
+	}
+	return dist
+}
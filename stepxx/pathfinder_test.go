@@ -0,0 +1,133 @@
+package main
+
+import "testing"
+
+// bfsDistances returns the number of moves from start to every tile of
+// maze, found without any of the code under test other than makeMove
+func bfsDistances(maze Maze, start Point) map[Point]int {
+	dist := map[Point]int{start: 0}
+	queue := []Point{start}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, dir := range directions {
+			n := makeMove(maze, p, dir)
+			if _, seen := dist[n]; !seen {
+				dist[n] = dist[p] + 1
+				queue = append(queue, n)
+			}
+		}
+	}
+	return dist
+}
+
+// openTiles returns every tile of maze that isn't a wall
+func openTiles(maze Maze) []Point {
+	var tiles []Point
+	for row, line := range maze {
+		for col := range line {
+			if maze.IsLegal(Point{row, col}) {
+				tiles = append(tiles, Point{row, col})
+			}
+		}
+	}
+	return tiles
+}
+
+func loadTestMaze(t testing.TB) Maze {
+	maze, err := loadMaze("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return maze
+}
+
+func TestPathFinderWalk(t *testing.T) {
+	maze := loadTestMaze(t)
+	tiles := openTiles(maze)
+
+	// every start would take a while, one in every few covers the whole maze
+	// as targets anyway
+	for i := 0; i < len(tiles); i += 11 {
+		start := tiles[i]
+		want := bfsDistances(maze, start)
+
+		for _, pf := range []PathFinder{
+			{maze: maze},
+			{maze: maze, distances: NewDistanceCache(maze)},
+		} {
+			for _, target := range tiles {
+				path := pf.walk(start, target)
+
+				d, ok := want[target]
+				if !ok {
+					if path != nil {
+						t.Errorf("walk(%v, %v) = %v, want nil for an unreachable target", start, target, path)
+					}
+					continue
+				}
+				if len(path) != d {
+					t.Errorf("walk(%v, %v) has %d steps, want %d (cached: %v)", start, target, len(path), d, pf.distances != nil)
+					continue
+				}
+
+				current := start
+				for _, next := range path {
+					if giveDirection(maze, current, next) == "" {
+						t.Fatalf("walk(%v, %v) jumps from %v to %v", start, target, current, next)
+					}
+					current = next
+				}
+				if current != target {
+					t.Errorf("walk(%v, %v) ends at %v", start, target, current)
+				}
+			}
+		}
+	}
+}
+
+func TestDistanceCacheRaggedMaze(t *testing.T) {
+	// the first row is shorter than the ones below it
+	maze := Maze{
+		"#####",
+		"#...#",
+		"#.#.......#",
+		"###########",
+	}
+	dc := NewDistanceCache(maze)
+
+	if d := dc.Distance(Point{2, 9}, Point{1, 1}); d != 9 {
+		t.Errorf("Distance((2,9), (1,1)) = %d, want 9", d)
+	}
+	if d := dc.Distance(Point{1, 1}, Point{2, 9}); d != 9 {
+		t.Errorf("Distance((1,1), (2,9)) = %d, want 9", d)
+	}
+}
+
+// benchmarkWalk walks across the whole of maze01, from the top left corner
+// to the bottom right one
+func benchmarkWalk(b *testing.B, cached bool) {
+	maze := loadTestMaze(b)
+	pf := PathFinder{maze: maze}
+	if cached {
+		pf.distances = NewDistanceCache(maze)
+	}
+	start, target := Point{1, 1}, Point{22, 26}
+	if pf.walk(start, target) == nil {
+		b.Fatalf("no path from %v to %v", start, target)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pf.walk(start, target)
+	}
+}
+
+func BenchmarkPathFinderWalk(b *testing.B) {
+	benchmarkWalk(b, false)
+}
+
+func BenchmarkPathFinderWalkCached(b *testing.B) {
+	benchmarkWalk(b, true)
+}
@@ -1,8 +1,8 @@
 package main
 
 import (
+	"io"
 	"log"
-	"os"
 )
 
 // Player is the player character \o/
@@ -42,51 +42,72 @@ func (p *Player) Kill() {
 }
 
 // Move processes player input
-func (p *Player) Move() {
-	input, err := readInput()
-	if err != nil {
-		log.Print("Error reading input:", err)
-		p.lives = 0
-		return
-	}
-
-	if input == "ESC" {
-		p.lives = 0
-	}
-
-	p.movePlayer(input)
+func (p *Player) Move(g *Game) {
+	p.movePlayer(g, g.input)
 }
 
-func (p *Player) movePlayer(dir string) {
-	p.position = makeMove(p.position, dir)
+func (p *Player) movePlayer(g *Game, dir string) {
+	p.position = makeMove(g.maze, p.position, dir)
 	row := p.position.row
 	col := p.position.col
 
 	removeDot := func(row, col int) {
-		maze[row] = maze[row][0:col] + " " + maze[row][col+1:]
+		g.maze[row] = g.maze[row][0:col] + " " + g.maze[row][col+1:]
 	}
 
-	switch maze[row][col] {
+	switch g.maze[row][col] {
 	case '.':
-		numDots--
-		p.score++
 		removeDot(row, col)
+		g.eatDot()
 	case 'X':
-		p.score += 10
 		removeDot(row, col)
+		g.eatPill()
 	}
+	g.eatFruit()
 }
 
-func readInput() (string, error) {
+// readInputs reads key presses from r on a single goroutine and delivers
+// them on the returned channel. The game loop polls the channel once per
+// tick, so it never blocks waiting for the terminal. The channel is closed
+// when r can't be read anymore.
+func readInputs(r io.Reader) <-chan string {
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		for {
+			input, err := readInput(r)
+			if err != nil {
+				log.Print("Error reading input:", err)
+				return
+			}
+			ch <- input
+		}
+	}()
+	return ch
+}
+
+func readInput(r io.Reader) (string, error) {
 	buffer := make([]byte, 100)
 
-	cnt, err := os.Stdin.Read(buffer)
+	cnt, err := r.Read(buffer)
 	if err != nil {
 		return "", err
 	}
 
-	if cnt == 1 && buffer[0] == 0x1b {
-		return "ESC", nil
+	if cnt == 1 {
+		switch buffer[0] {
+		case 0x1b:
+			return "ESC", nil
+		case '\n', '\r':
+			return "ENTER", nil
+		case 0x7f, '\b':
+			return "BACKSPACE", nil
+		}
+		// other printable keys are returned as they are, so they can be
+		// used for shortcuts and typing names
+		if buffer[0] >= ' ' && buffer[0] < 0x7f {
+			return string(buffer[:1]), nil
+		}
 	} else if cnt >= 3 {
 		if buffer[0] == 0x1b && buffer[1] == '[' {
 			switch buffer[2] {
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Profile accumulates a player's statistics across sessions
+type Profile struct {
+	GamesPlayed     int                   `json:"games_played"`
+	DotsEaten       int                   `json:"dots_eaten"`
+	GhostsEaten     int                   `json:"ghosts_eaten"`
+	DeathsByGhost   map[string]int        `json:"deaths_by_ghost"`
+	LevelsCleared   int                   `json:"levels_cleared"`
+	LongestSurvival time.Duration         `json:"longest_survival"`
+	Mazes           map[string]*MazeStats `json:"mazes"`
+	Achievements    map[string]time.Time  `json:"achievements"`
+}
+
+// MazeStats are a profile's results on a single maze
+type MazeStats struct {
+	Games      int `json:"games"`
+	TotalScore int `json:"total_score"`
+	BestScore  int `json:"best_score"`
+}
+
+// AverageScore returns the mean score over every game on the maze
+func (m *MazeStats) AverageScore() float64 {
+	if m.Games == 0 {
+		return 0
+	}
+	return float64(m.TotalScore) / float64(m.Games)
+}
+
+// Record adds the outcome of a finished game on the named maze
+func (p *Profile) Record(maze string, game *Game) {
+	if p.DeathsByGhost == nil {
+		p.DeathsByGhost = make(map[string]int)
+	}
+	if p.Mazes == nil {
+		p.Mazes = make(map[string]*MazeStats)
+	}
+
+	p.GamesPlayed++
+	p.DotsEaten += game.stats.Dots
+	p.GhostsEaten += game.stats.GhostsEaten
+	for kind, deaths := range game.stats.DeathsBy {
+		p.DeathsByGhost[kind] += deaths
+	}
+	if game.numDots == 0 {
+		p.LevelsCleared++
+	}
+
+	survived := time.Duration(game.stats.Ticks) * time.Second / time.Duration(game.rules.FrameRate)
+	p.LongestSurvival = max(p.LongestSurvival, survived)
+
+	m, ok := p.Mazes[maze]
+	if !ok {
+		m = &MazeStats{}
+		p.Mazes[maze] = m
+	}
+	m.Games++
+	m.TotalScore += game.player.score
+	m.BestScore = max(m.BestScore, game.player.score)
+}
+
+// Unlock records achievements, keeping the earliest time each was unlocked
+func (p *Profile) Unlock(unlocked map[string]time.Time) {
+	if p.Achievements == nil {
+		p.Achievements = make(map[string]time.Time)
+	}
+	for id, at := range unlocked {
+		if old, ok := p.Achievements[id]; !ok || at.Before(old) {
+			p.Achievements[id] = at
+		}
+	}
+}
+
+// ProfileStore keeps every profile in a JSON file, updated the same way as
+// the high scores so that concurrent games don't lose each other's stats
+type ProfileStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewProfileStore uses the file at path, or the default location in the
+// XDG data directory if path is empty
+func NewProfileStore(path string) (*ProfileStore, error) {
+	path, err := dataFile(path, "profiles.json")
+	if err != nil {
+		return nil, err
+	}
+	return &ProfileStore{path: path}, nil
+}
+
+// Profiles returns every profile by name
+func (s *ProfileStore) Profiles() (map[string]*Profile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read()
+}
+
+// Update applies fn to the named profile, creating it if needed, and saves
+// the result
+func (s *ProfileStore) Update(name string, fn func(p *Profile)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := lockFile(s.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	profiles, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	p, ok := profiles[name]
+	if !ok {
+		p = &Profile{}
+		profiles[name] = p
+	}
+	fn(p)
+
+	data, err := json.MarshalIndent(profiles, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, data)
+}
+
+func (s *ProfileStore) read() (map[string]*Profile, error) {
+	profiles := make(map[string]*Profile)
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return profiles, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &profiles)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", s.path, err)
+	}
+	return profiles, nil
+}
+
+// runStats prints the lifetime statistics of one or every profile
+func runStats(args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	name := flags.String("profile", "", "only show this profile")
+	flags.Parse(args)
+
+	store, err := NewProfileStore(*profileFile)
+	if err != nil {
+		return err
+	}
+
+	profiles, err := store.Profiles()
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for n := range profiles {
+		if *name == "" || n == *name {
+			names = append(names, n)
+		}
+	}
+	if len(names) == 0 {
+		if *name != "" {
+			return fmt.Errorf("no profile called %q", *name)
+		}
+		fmt.Println("No games played yet")
+		return nil
+	}
+	sort.Strings(names)
+
+	for i, n := range names {
+		if i > 0 {
+			fmt.Println()
+		}
+		printProfile(n, profiles[n])
+	}
+	return nil
+}
+
+func printProfile(name string, p *Profile) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Profile:\t%s\n", name)
+	fmt.Fprintf(w, "Games played:\t%d\n", p.GamesPlayed)
+	fmt.Fprintf(w, "Dots eaten:\t%d\n", p.DotsEaten)
+	fmt.Fprintf(w, "Ghosts eaten:\t%d\n", p.GhostsEaten)
+	fmt.Fprintf(w, "Levels cleared:\t%d\n", p.LevelsCleared)
+	fmt.Fprintf(w, "Longest survival:\t%s\n", p.LongestSurvival.Round(time.Second))
+
+	var kinds []string
+	for kind := range p.DeathsByGhost {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		fmt.Fprintf(w, "Deaths by %s:\t%d\n", kind, p.DeathsByGhost[kind])
+	}
+
+	var mazes []string
+	for maze := range p.Mazes {
+		mazes = append(mazes, maze)
+	}
+	sort.Strings(mazes)
+	for _, maze := range mazes {
+		m := p.Mazes[maze]
+		fmt.Fprintf(w, "Average score on %s:\t%.1f (best %d, %d games)\n", maze, m.AverageScore(), m.BestScore, m.Games)
+	}
+
+	fmt.Fprintf(w, "Achievements:\t%d of %d\n", len(p.Achievements), len(achievements))
+	for _, a := range achievements {
+		if at, ok := p.Achievements[a.ID]; ok {
+			fmt.Fprintf(w, "  %s:\t%s (%s)\n", a.Name, a.Description, at.Format(time.DateOnly))
+		}
+	}
+	w.Flush()
+}
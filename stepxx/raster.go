@@ -0,0 +1,286 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+)
+
+// Palette indices of the raster renderer
+const (
+	rasterBackground = iota
+	rasterWall
+	rasterDot
+	rasterPill
+	rasterFruit
+	rasterPlayer
+	rasterGhost
+	rasterChaser
+	rasterBlueGhost
+)
+
+// rasterPalette takes the colours of the current theme, falling back to the
+// classic colours where the theme has none, e.g. in monochrome
+func rasterPalette() color.Palette {
+	classic := themes["classic"]
+	colour := func(s, fallback Style) color.Color {
+		for _, c := range []Colour{s.FG, s.BG, fallback.FG} {
+			if r, g, b, ok := c.rgb(); ok {
+				return color.RGBA{uint8(r), uint8(g), uint8(b), 0xff}
+			}
+		}
+		return color.White
+	}
+
+	return color.Palette{
+		rasterBackground: color.Black,
+		rasterWall:       colour(theme.Wall, classic.Wall),
+		rasterDot:        colour(theme.Dot, classic.Dot),
+		rasterPill:       colour(theme.Pill, classic.Pill),
+		rasterFruit:      colour(theme.Fruit, classic.Fruit),
+		rasterPlayer:     colour(theme.Player, classic.Player),
+		rasterGhost:      colour(theme.Ghost, classic.Ghost),
+		rasterChaser:     colour(theme.Chaser, classic.Chaser),
+		rasterBlueGhost:  colour(theme.BlueGhost, classic.BlueGhost),
+	}
+}
+
+// minTile is the smallest tile size, in pixels, the sprites can be told
+// apart at
+const minTile = 4
+
+// Raster draws games as images, with a square tile of the given size in
+// pixels for every tile of the maze
+type Raster struct {
+	tile  int
+	walls *image.Paletted // drawn once, since walls never move
+}
+
+// NewRaster prepares to draw games on maze
+func NewRaster(maze Maze, tile int) *Raster {
+	width := maze.Width()
+
+	r := &Raster{tile: tile}
+	r.walls = image.NewPaletted(image.Rect(0, 0, width*tile, len(maze)*tile), rasterPalette())
+
+	outer := outerWalls(maze)
+	for row, line := range maze {
+		for col := range line {
+			if isWall(maze, row, col) {
+				r.drawWall(maze, row, col, outer[Point{row, col}])
+			}
+		}
+	}
+	return r
+}
+
+// drawWall draws the outline of the wall at row and col, the same way as
+// the box-drawing walls in the terminal, with thicker lines for the outer
+// border
+func (r *Raster) drawWall(maze Maze, row, col int, outer bool) {
+	x, y, t := col*r.tile, row*r.tile, r.tile
+	c := t / 2
+	w := max(t/8, 1)
+	if outer {
+		w *= 2
+	}
+	lo, hi := c-w/2, c-w/2+w
+
+	bits := wallBits(maze, row, col)
+	if bits == 0 {
+		if !isSolid(maze, row, col) {
+			fillRect(r.walls, x+lo, y+lo, x+hi, y+hi, rasterWall)
+		}
+		return
+	}
+	if bits&wallUp != 0 {
+		fillRect(r.walls, x+lo, y, x+hi, y+hi, rasterWall)
+	}
+	if bits&wallDown != 0 {
+		fillRect(r.walls, x+lo, y+lo, x+hi, y+t, rasterWall)
+	}
+	if bits&wallLeft != 0 {
+		fillRect(r.walls, x, y+lo, x+hi, y+hi, rasterWall)
+	}
+	if bits&wallRight != 0 {
+		fillRect(r.walls, x+lo, y+lo, x+t, y+hi, rasterWall)
+	}
+}
+
+// Frame draws the current state of g
+func (r *Raster) Frame(g *Game) *image.Paletted {
+	img := image.NewPaletted(r.walls.Rect, r.walls.Palette)
+	copy(img.Pix, r.walls.Pix)
+
+	t := r.tile
+	for row, line := range g.maze {
+		for col, chr := range line {
+			x, y := col*t, row*t
+			switch chr {
+			case '.':
+				d := max(t/5, 1)
+				fillRect(img, x+(t-d)/2, y+(t-d)/2, x+(t-d)/2+d, y+(t-d)/2+d, rasterDot)
+			case 'X':
+				fillCircle(img, x+t/2, y+t/2, t/3, rasterPill)
+			}
+		}
+	}
+
+	if pos, ok := g.FruitPos(); ok {
+		fillCircle(img, pos.col*t+t/2, pos.row*t+t/2, t/3, rasterFruit)
+	}
+
+	for _, s := range g.sprites {
+		row, col := s.Pos()
+		x, y := col*t, row*t
+		switch s := s.(type) {
+		case *Player:
+			r.drawPlayer(img, x, y)
+		case *Chaser:
+			r.drawGhost(img, x, y, rasterChaser, s.status)
+		case *Ghost:
+			r.drawGhost(img, x, y, rasterGhost, s.status)
+		}
+	}
+	return img
+}
+
+// drawPlayer draws a circle with a mouth facing right
+func (r *Raster) drawPlayer(img *image.Paletted, x, y int) {
+	c, radius := r.tile/2, r.tile/2-1
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			mouth := dx > 0 && abs(dy) < dx/2+1
+			if dx*dx+dy*dy <= radius*radius && !mouth {
+				img.SetColorIndex(x+c+dx, y+c+dy, rasterPlayer)
+			}
+		}
+	}
+}
+
+// drawGhost draws a dome on top of a square skirt
+func (r *Raster) drawGhost(img *image.Paletted, x, y int, colour uint8, status GhostStatus) {
+	if status == GhostStatusBlue {
+		colour = rasterBlueGhost
+	}
+	c, radius := r.tile/2, r.tile/2-1
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			if dy >= 0 || dx*dx+dy*dy <= radius*radius {
+				img.SetColorIndex(x+c+dx, y+c+dy, colour)
+			}
+		}
+	}
+}
+
+func fillRect(img *image.Paletted, x0, y0, x1, y1 int, colour uint8) {
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			img.SetColorIndex(x, y, colour)
+		}
+	}
+}
+
+func fillCircle(img *image.Paletted, cx, cy, radius int, colour uint8) {
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			if dx*dx+dy*dy <= radius*radius {
+				img.SetColorIndex(cx+dx, cy+dy, colour)
+			}
+		}
+	}
+}
+
+// runRender turns a replay into an animated GIF, one frame per tick
+func runRender(args []string) error {
+	flags := flag.NewFlagSet("render", flag.ExitOnError)
+	replayFile := flags.String("replay", "", "replay to render, recorded with --record-replay")
+	out := flags.String("out", "", "GIF file to write")
+	tile := flags.Int("tile", 16, "size of a maze tile in pixels")
+	flags.Parse(args)
+
+	if *replayFile == "" || *out == "" {
+		return errors.New("usage: render --replay game.rep --out game.gif [--tile 16]")
+	}
+	if *tile < minTile {
+		return fmt.Errorf("tiles must be at least %d pixels", minTile)
+	}
+
+	replay, err := LoadReplay(*replayFile)
+	if err != nil {
+		return err
+	}
+
+	var anim gif.GIF
+	var raster *Raster
+	err = replay.Play(func(g *Game) {
+		if raster == nil {
+			raster = NewRaster(g.maze, *tile)
+		}
+		anim.Image = append(anim.Image, raster.Frame(g))
+		anim.Delay = append(anim.Delay, 100/cfg.FrameRate)
+	})
+	if err != nil {
+		return err
+	}
+	// linger on the end of the game before looping
+	anim.Delay[len(anim.Delay)-1] = 200
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = gif.EncodeAll(f, &anim)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// runScreenshot draws a maze as it looks at the start of a game as a PNG
+func runScreenshot(args []string) error {
+	flags := flag.NewFlagSet("screenshot", flag.ExitOnError)
+	mazePath := flags.String("maze", *mazeFile, "maze file to draw (default built-in maze01.txt)")
+	out := flags.String("out", "", "PNG file to write")
+	tile := flags.Int("tile", 16, "size of a maze tile in pixels")
+	flags.Parse(args)
+
+	if *out == "" {
+		return errors.New("usage: screenshot --maze maze01.txt --out maze.png [--tile 16]")
+	}
+	if *tile < minTile {
+		return fmt.Errorf("tiles must be at least %d pixels", minTile)
+	}
+
+	err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	maze, err := loadMaze(*mazePath)
+	if err != nil {
+		return err
+	}
+
+	g := NewGame(withDifficultyGhosts(maze), 0)
+	img := NewRaster(g.maze, *tile).Frame(g)
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = png.Encode(f, img)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
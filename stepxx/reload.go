@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"time"
+)
+
+// configPollInterval is how often the configuration file is checked for
+// changes while playing
+const configPollInterval = time.Second
+
+// configWatcher notices when the configuration file is saved, by polling
+// its modification time
+type configWatcher struct {
+	path    string
+	modTime time.Time
+	checked time.Time
+}
+
+// newConfigWatcher watches the file at path. It returns nil for the
+// built-in configuration, which never changes.
+func newConfigWatcher(path string) *configWatcher {
+	if path == "" {
+		return nil
+	}
+
+	w := &configWatcher{path: path, checked: time.Now()}
+	if info, err := os.Stat(path); err == nil {
+		w.modTime = info.ModTime()
+	}
+	return w
+}
+
+// changed reports whether the file was modified since the last time it
+// returned true. A file that is missing, e.g. while an editor replaces it,
+// doesn't count as a change.
+func (w *configWatcher) changed() bool {
+	if w == nil || time.Since(w.checked) < configPollInterval {
+		return false
+	}
+	w.checked = time.Now()
+
+	info, err := os.Stat(w.path)
+	if err != nil || info.ModTime().Equal(w.modTime) {
+		return false
+	}
+	w.modTime = info.ModTime()
+	return true
+}
+
+// reloadConfig loads the configuration again and applies the settings that
+// only affect how the game looks and how fast it runs: glyphs, colours and
+// the frame rate. Game rules keep their values until the next launch, and
+// nothing changes if the new configuration has errors.
+func reloadConfig(game *Game) (warnings []string, err error) {
+	old := cfg
+	err = loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Difficulty = old.Difficulty
+	cfg.Lives = old.Lives
+	cfg.GhostSpeed = old.GhostSpeed
+	cfg.PillDurationSecs = old.PillDurationSecs
+	cfg.Ghosts = old.Ghosts
+	cfg.ExtraLifeScore = old.ExtraLifeScore
+	cfg.ExtraLifeEvery = old.ExtraLifeEvery
+	cfg.MaxLives = old.MaxLives
+
+	warnings = fitGlyphs()
+	game.Restyle()
+	return warnings, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// replayVersion is bumped whenever a change to the game would make old
+// replays play out differently
+const replayVersion = 2
+
+// Replay is everything needed to play a game again exactly as it
+// happened: games are deterministic given the maze, the seed, the rules in
+// the configuration and the input on every tick.
+type Replay struct {
+	Version int      `json:"version"`
+	Config  Config   `json:"config"`
+	Maze    Maze     `json:"maze"`
+	Seed    int64    `json:"seed"`
+	Inputs  []string `json:"inputs"`
+}
+
+// NewReplay records g as it has been played so far
+func NewReplay(g *Game) Replay {
+	return Replay{
+		Version: replayVersion,
+		Config:  g.rules,
+		Maze:    g.start,
+		Seed:    g.seed,
+		Inputs:  append([]string(nil), g.inputs...),
+	}
+}
+
+// LoadReplay reads a replay saved with Save
+func LoadReplay(path string) (Replay, error) {
+	var r Replay
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return r, err
+	}
+
+	err = json.Unmarshal(data, &r)
+	if err != nil {
+		return r, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if r.Version != replayVersion {
+		return r, fmt.Errorf("%s is a version %d replay, this game plays version %d", path, r.Version, replayVersion)
+	}
+	return r, nil
+}
+
+// Save writes the replay to the file at path
+func (r Replay) Save(path string) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Play replaces the configuration with the one the game was played with,
+// and plays the game again calling fn with its state at the start and
+// after every tick
+func (r Replay) Play(fn func(g *Game)) error {
+	t, err := r.Config.validate()
+	if err != nil {
+		return fmt.Errorf("replay configuration: %w", err)
+	}
+	cfg, theme = r.Config, t
+
+	g := NewGame(r.Maze, r.Seed)
+	fn(g)
+	for _, input := range r.Inputs {
+		g.Update(input)
+		fn(g)
+	}
+	return nil
+}
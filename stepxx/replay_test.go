@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReplayPlaysTheSameGame(t *testing.T) {
+	oldCfg, oldTheme := cfg, theme
+	defer func() { cfg, theme = oldCfg, oldTheme }()
+	if err := loadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	maze := loadTestMaze(t)
+
+	tests := []struct {
+		name   string
+		ghosts string
+		seed   int64
+		// changeAt is the tick at which the frame rate is changed behind
+		// the game's back, 0 for never
+		changeAt int
+	}{
+		{"maze ghosts", "maze", 1, 0},
+		{"random ghosts", "random", 2, 0},
+		{"chasers", "chaser", 3, 0},
+		{"frame rate changed mid-game", "chaser", 1, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg = oldCfg
+			if err := loadConfig(); err != nil {
+				t.Fatal(err)
+			}
+			cfg.Ghosts = tt.ghosts
+
+			bot, _ := NewController("greedy", tt.seed)
+			g := NewGame(withDifficultyGhosts(maze), tt.seed)
+			for !g.Over() && g.stats.Ticks < 3000 {
+				if tt.changeAt > 0 && g.stats.Ticks == tt.changeAt {
+					cfg.FrameRate = 1
+				}
+				g.Update(bot.Direction(View{g}))
+			}
+
+			path := filepath.Join(t.TempDir(), "game.rep")
+			if err := NewReplay(g).Save(path); err != nil {
+				t.Fatal(err)
+			}
+			replay, err := LoadReplay(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var last *Game
+			if err := replay.Play(func(r *Game) { last = r }); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(last.stats, g.stats) {
+				t.Errorf("replayed stats %+v, want %+v", last.stats, g.stats)
+			}
+			if last.player.score != g.player.score || last.player.lives != g.player.lives {
+				t.Errorf("replayed score %d with %d lives, want %d with %d lives",
+					last.player.score, last.player.lives, g.player.score, g.player.lives)
+			}
+		})
+	}
+}
+
+func TestLoadReplayRejectsOtherVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.rep")
+	if err := os.WriteFile(path, []byte(`{"version": 1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadReplay(path); err == nil {
+		t.Error("LoadReplay accepted a version 1 replay")
+	}
+}
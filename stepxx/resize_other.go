@@ -0,0 +1,9 @@
+//go:build !unix
+
+package main
+
+// watchTerminalSize reads the terminal size once, there is no resize signal
+// to listen to on this platform
+func watchTerminalSize() {
+	updateTerminalSize()
+}
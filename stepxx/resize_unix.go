@@ -0,0 +1,23 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// watchTerminalSize reads the terminal size now and again every time the
+// terminal is resized
+func watchTerminalSize() {
+	updateTerminalSize()
+
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	go func() {
+		for range winch {
+			updateTerminalSize()
+		}
+	}()
+}
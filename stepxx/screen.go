@@ -1,11 +1,14 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
-
-	"github.com/danicat/simpleansi"
+	"strings"
+	"time"
 )
 
 func initialise() {
@@ -29,10 +32,117 @@ func cleanup() {
 	}
 }
 
-func moveCursor(row, col int) {
+// clearScreen is simpleansi.ClearScreen writing to w instead of stdout
+func clearScreen(w io.Writer) {
+	fmt.Fprint(w, "\x1b[2J")
+	moveCursor(w, 0, 0)
+}
+
+func moveCursor(w io.Writer, row, col int) {
+	if cfg.UseEmoji {
+		col = col * 2
+	}
+	moveCursorColumn(w, row, col)
+}
+
+// moveCursorColumn moves to a terminal column rather than a maze column,
+// for text that doesn't line up with the maze tiles
+func moveCursorColumn(w io.Writer, row, col int) {
+	fmt.Fprintf(w, "\x1b[%d;%df", row+1, col+1)
+}
+
+// drawBox writes lines centred over the visible part of the maze, framed
+// and on a blank background. The line at index highlight, if any, is shown
+// in reverse video.
+func drawBox(w io.Writer, v viewport, lines []string, highlight int) {
+	if v.tooSmall() {
+		return
+	}
+
+	inner := 0
+	for _, line := range lines {
+		inner = max(inner, len(line))
+	}
+	inner += 2
+
+	top := max((v.rows-len(lines)-2)/2, 0)
+	left := max((v.width()-inner-2)/2, 0)
+
+	moveCursorColumn(w, top, left)
+	fmt.Fprint(w, "+"+strings.Repeat("-", inner)+"+")
+	for i, line := range lines {
+		moveCursorColumn(w, top+i+1, left)
+		text := " " + line + strings.Repeat(" ", inner-len(line)-1)
+		if i == highlight {
+			text = "\x1b[7m" + text + "\x1b[0m"
+		}
+		fmt.Fprint(w, "|"+text+"|")
+	}
+	moveCursorColumn(w, top+len(lines)+1, left)
+	fmt.Fprint(w, "+"+strings.Repeat("-", inner)+"+")
+}
+
+// flushFrame writes a complete frame to the output and to any spectators,
+// so everyone watching sees exactly the same bytes.
+func flushFrame(frame *bytes.Buffer) {
+	output.Write(frame.Bytes())
+	spectators.Broadcast(frame.Bytes())
+	frame.Reset()
+}
+
+// maxFruitHistory is how many of the fruits collected the status bar shows
+const maxFruitHistory = 7
+
+// statusBar returns the line under the maze. Every field has a fixed width
+// so nothing moves around as the numbers change.
+func statusBar(g *Game) string {
+	lives := fmt.Sprintf("%-2d", g.player.lives)
 	if cfg.UseEmoji {
-		simpleansi.MoveCursor(row, col*2)
-	} else {
-		simpleansi.MoveCursor(row, col)
+		lives = getLivesAsEmoji(g.player.lives)
+		lives += strings.Repeat("  ", max(g.rules.MaxLives-g.player.lives, 0))
+	}
+	fruit := strings.Repeat(cfg.Fruit, min(g.stats.Fruits, maxFruitHistory))
+
+	status := fmt.Sprintf("Score: %-6d Lives: %s Fruit: %s", g.player.score, lives, fruit)
+	if spectators != nil {
+		status += fmt.Sprint("\tSpectators: ", spectators.Count())
 	}
+	return status
+}
+
+// getLivesAsEmoji concatenates the player's glyph once per life
+func getLivesAsEmoji(lives int) string {
+	return strings.Repeat(cfg.Player, lives)
+}
+
+// noticeDuration is how long each notice stays on screen
+const noticeDuration = 3 * time.Second
+
+// notices are short messages shown one at a time under the score
+type notices struct {
+	queue []string
+	until time.Time
+}
+
+// add queues text to be shown after the notices already waiting
+func (n *notices) add(text string) {
+	n.queue = append(n.queue, text)
+}
+
+// draw writes the current notice on the line below the score
+func (n *notices) draw(w io.Writer, v viewport) {
+	if len(n.queue) == 0 {
+		return
+	}
+	if n.until.IsZero() {
+		n.until = time.Now().Add(noticeDuration)
+	} else if time.Now().After(n.until) {
+		n.queue = n.queue[1:]
+		n.until = time.Time{}
+		n.draw(w, v)
+		return
+	}
+
+	moveCursor(w, v.hudRow()+1, 0)
+	fmt.Fprint(w, "\x1b[2K"+theme.HUD.paint(n.queue[0]))
 }
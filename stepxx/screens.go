@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// titleScreen shows the maze name, its high scores and any warnings about
+// the setup. It returns true when the player presses Enter to play and
+// false when they press ESC to quit.
+func titleScreen(maze Maze, store *HighScoreStore, warnings []string, input <-chan string) bool {
+	lines := []string{
+		"P A C   G O",
+		"",
+		"Maze: " + mazeName(),
+		"Profile: " + *profile,
+	}
+	for _, warning := range warnings {
+		lines = append(lines, "Warning: "+warning)
+	}
+	lines = append(lines, "", "HIGH SCORES")
+
+	scores, err := store.Scores(scoreKey(maze, cfg))
+	switch {
+	case err != nil:
+		lines = append(lines, "Unavailable: "+err.Error())
+	case len(scores) == 0:
+		lines = append(lines, "No games played yet")
+	}
+	for i, score := range scores {
+		lines = append(lines, fmt.Sprintf("%2d. %-10s %6d", i+1, score.Name, score.Score))
+	}
+	lines = append(lines, "", "Enter: play", "ESC: quit")
+
+	preview := NewGame(withDifficultyGhosts(maze), 0)
+	return waitScreen(input, func(w io.Writer) {
+		drawBox(w, printScreen(w, preview), lines, -1)
+	})
+}
+
+// gameOverScreen shows the final stats of game. It returns true when the
+// player presses Enter to play again and false when they press ESC to go
+// back to the title screen.
+func gameOverScreen(game *Game, input <-chan string) bool {
+	title := "GAME OVER"
+	if game.numDots == 0 {
+		title = "MAZE CLEARED!"
+	}
+
+	survived := time.Duration(game.stats.Ticks) * time.Second / time.Duration(game.rules.FrameRate)
+	lines := []string{
+		title,
+		"",
+		fmt.Sprintf("Score:        %6d", game.player.score),
+		fmt.Sprintf("Dots eaten:   %6d", game.stats.Dots),
+		fmt.Sprintf("Pills eaten:  %6d", game.stats.Pills),
+		fmt.Sprintf("Ghosts eaten: %6d", game.stats.GhostsEaten),
+		fmt.Sprintf("Time:         %6s", survived.Round(time.Second)),
+		"",
+		"Enter: play again",
+		"ESC: title screen",
+	}
+
+	return waitScreen(input, func(w io.Writer) {
+		drawBox(w, printScreen(w, game), lines, -1)
+	})
+}
+
+// maxNameLength is how many characters fit in the high score table
+const maxNameLength = 10
+
+// nameEntryScreen asks for the name to put next to a new high score. It
+// returns false if the player presses ESC to skip it.
+func nameEntryScreen(game *Game, input <-chan string) (string, bool) {
+	var name []byte
+	var frame bytes.Buffer
+	for {
+		select {
+		case inp, ok := <-input:
+			switch {
+			case !ok, inp == "ESC":
+				return "", false
+			case inp == "ENTER":
+				if len(name) == 0 {
+					return "anonymous", true
+				}
+				return string(name), true
+			case inp == "BACKSPACE":
+				if len(name) > 0 {
+					name = name[:len(name)-1]
+				}
+			case len(inp) == 1 && len(name) < maxNameLength:
+				name = append(name, inp[0])
+			}
+		default:
+		}
+
+		cursor := strings.Repeat("_", maxNameLength-len(name))
+		drawBox(&frame, printScreen(&frame, game), []string{
+			"NEW HIGH SCORE!",
+			"",
+			fmt.Sprintf("Score: %d", game.player.score),
+			"",
+			"Name: " + string(name) + cursor,
+			"",
+			"Enter: save",
+			"ESC: skip",
+		}, -1)
+		flushFrame(&frame)
+		time.Sleep(time.Second / time.Duration(cfg.FrameRate))
+	}
+}
+
+// waitScreen keeps redrawing a static screen, so spectators joining late
+// see it too, until the player presses Enter (true) or ESC (false)
+func waitScreen(input <-chan string, draw func(w io.Writer)) bool {
+	var frame bytes.Buffer
+	for {
+		select {
+		case inp, ok := <-input:
+			switch {
+			case !ok, inp == "ESC":
+				return false
+			case inp == "ENTER":
+				return true
+			}
+		default:
+		}
+
+		draw(&frame)
+		flushFrame(&frame)
+		time.Sleep(time.Second / time.Duration(cfg.FrameRate))
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"net"
+	"sync"
+)
+
+// Spectators broadcasts rendered frames to read-only viewers connected over
+// TCP. Any terminal can watch a running game with `nc <host> <port>`.
+//
+// A nil *Spectators is valid and does nothing, so callers don't need to
+// check whether spectating is enabled.
+type Spectators struct {
+	ln      net.Listener
+	mu      sync.Mutex
+	clients map[net.Conn]chan []byte
+}
+
+// ListenSpectators starts accepting spectators on addr
+func ListenSpectators(addr string) (*Spectators, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Spectators{
+		ln:      ln,
+		clients: make(map[net.Conn]chan []byte),
+	}
+	go s.accept()
+	return s, nil
+}
+
+func (s *Spectators) accept() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			// listener closed
+			return
+		}
+
+		// a single slot is enough: every frame redraws the whole screen,
+		// so a slow spectator can skip frames without losing anything
+		ch := make(chan []byte, 1)
+		s.mu.Lock()
+		s.clients[conn] = ch
+		s.mu.Unlock()
+
+		go s.send(conn, ch)
+	}
+}
+
+func (s *Spectators) send(conn net.Conn, ch chan []byte) {
+	defer s.drop(conn)
+	for frame := range ch {
+		if _, err := conn.Write(frame); err != nil {
+			// nothing to report: the terminal is busy drawing the game,
+			// and the spectator count in the status bar goes down
+			return
+		}
+	}
+}
+
+func (s *Spectators) drop(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ch, ok := s.clients[conn]; ok {
+		close(ch)
+		delete(s.clients, conn)
+	}
+	conn.Close()
+}
+
+// Broadcast sends a copy of frame to every connected spectator. Spectators
+// that are still busy with the previous frame miss this one.
+func (s *Spectators) Broadcast(frame []byte) {
+	if s == nil {
+		return
+	}
+
+	buf := make([]byte, len(frame))
+	copy(buf, frame)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ch := range s.clients {
+		select {
+		case ch <- buf:
+		default:
+		}
+	}
+}
+
+// Count returns the number of connected spectators
+func (s *Spectators) Count() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.clients)
+}
+
+// Close stops accepting spectators and disconnects the current ones
+func (s *Spectators) Close() {
+	if s == nil {
+		return
+	}
+	s.ln.Close()
+
+	s.mu.Lock()
+	conns := make([]net.Conn, 0, len(s.clients))
+	for conn := range s.clients {
+		conns = append(conns, conn)
+	}
+	s.mu.Unlock()
+
+	for _, conn := range conns {
+		s.drop(conn)
+	}
+}
@@ -4,7 +4,7 @@ import "errors"
 
 // Sprite is any game character
 type Sprite interface {
-	Move()
+	Move(g *Game)
 	Pos() (int, int)
 	Img() string
 }
@@ -13,56 +13,75 @@ type Point struct {
 	row, col int
 }
 
-func (p Point) Up() (Point, error) {
+func (p Point) Up(maze Maze) (Point, error) {
 	p.row--
 	if p.row < 0 {
 		p.row = len(maze) - 1
 	}
-	if !IsLegal(p) {
+	if !maze.IsLegal(p) {
 		return Point{}, errors.New("invalid position")
 	}
 	return p, nil
 }
 
-func (p Point) Down() (Point, error) {
+func (p Point) Down(maze Maze) (Point, error) {
 	p.row++
 	if p.row == len(maze) {
 		p.row = 0
 	}
-	if !IsLegal(p) {
+	if !maze.IsLegal(p) {
 		return Point{}, errors.New("invalid position")
 	}
 	return p, nil
 }
 
-func (p Point) Left() (Point, error) {
+func (p Point) Left(maze Maze) (Point, error) {
 	p.col--
 	if p.col < 0 {
-		p.col = len(maze[0]) - 1
+		p.col = maze.Width() - 1
 	}
-	if !IsLegal(p) {
+	if !maze.IsLegal(p) {
 		return Point{}, errors.New("invalid position")
 	}
 	return p, nil
 }
 
-func (p Point) Right() (Point, error) {
+func (p Point) Right(maze Maze) (Point, error) {
 	p.col++
-	if p.col == len(maze[0]) {
+	// only a row as wide as the maze wraps around, shorter ones are walled
+	// off past their end
+	if p.col >= len(maze[p.row]) && p.col == maze.Width() {
 		p.col = 0
 	}
-	if !IsLegal(p) {
+	if !maze.IsLegal(p) {
 		return Point{}, errors.New("invalid position")
 	}
 	return p, nil
 }
 
-func IsLegal(pos Point) bool {
-	return maze[pos.row][pos.col] != '#'
+// Maze is the static layout of a level, one string per row
+type Maze []string
+
+// Width returns the length of the longest row
+func (maze Maze) Width() int {
+	width := 0
+	for _, line := range maze {
+		width = max(width, len(line))
+	}
+	return width
+}
+
+func (maze Maze) IsLegal(pos Point) bool {
+	line := maze[pos.row]
+	// rows shorter than the widest one are walled off past their end
+	if pos.col >= len(line) {
+		return false
+	}
+	return line[pos.col] != '#'
 }
 
-func makeMove(oldPos Point, dir string) Point {
-	var fn func() (Point, error)
+func makeMove(maze Maze, oldPos Point, dir string) Point {
+	var fn func(Maze) (Point, error)
 	switch dir {
 	case "UP":
 		fn = oldPos.Up
@@ -76,7 +95,7 @@ func makeMove(oldPos Point, dir string) Point {
 		return oldPos
 	}
 
-	pos, err := fn()
+	pos, err := fn(maze)
 	if err != nil {
 		return oldPos
 	}
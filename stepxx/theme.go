@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Colour is a colour in "#rrggbb" notation, or empty for the terminal's
+// default
+type Colour string
+
+// rgb returns the red, green and blue components of c, and false for the
+// default colour
+func (c Colour) rgb() (r, g, b int, ok bool) {
+	if len(c) != 7 || c[0] != '#' {
+		return 0, 0, 0, false
+	}
+	v, err := strconv.ParseUint(string(c[1:]), 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return int(v >> 16 & 0xff), int(v >> 8 & 0xff), int(v & 0xff), true
+}
+
+// Style is how a kind of tile is drawn
+type Style struct {
+	FG      Colour
+	BG      Colour
+	Bold    bool
+	Reverse bool
+}
+
+// paint wraps text in the escape sequences for s, using as many colours as
+// the terminal supports
+func (s Style) paint(text string) string {
+	var codes []string
+	if s.Bold {
+		codes = append(codes, "1")
+	}
+	if s.Reverse {
+		codes = append(codes, "7")
+	}
+	if code := colourLevel.code(s.FG, false); code != "" {
+		codes = append(codes, code)
+	}
+	if code := colourLevel.code(s.BG, true); code != "" {
+		codes = append(codes, code)
+	}
+	if len(codes) == 0 {
+		return text
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m" + text + "\x1b[0m"
+}
+
+// solid returns the style for a wall drawn as a block rather than a line:
+// the wall colour becomes the background, and without colours the block is
+// drawn in reverse video so it still shows up
+func (s Style) solid() Style {
+	if s.BG == "" {
+		s.BG = s.FG
+	}
+	if colourLevel == colourNone || s.BG == "" {
+		s.Reverse = true
+	}
+	return s
+}
+
+// Theme styles every kind of tile on screen
+type Theme struct {
+	Wall      Style
+	Dot       Style
+	Pill      Style
+	Fruit     Style
+	Player    Style
+	Ghost     Style
+	Chaser    Style
+	BlueGhost Style
+	HUD       Style
+}
+
+// themes are the colour themes that can be chosen in the configuration
+var themes = map[string]Theme{
+	"classic": {
+		Wall:      Style{FG: "#2121de"},
+		Dot:       Style{FG: "#ffb8ae"},
+		Pill:      Style{FG: "#ffb8ae", Bold: true},
+		Fruit:     Style{FG: "#ff0000"},
+		Player:    Style{FG: "#ffff00", Bold: true},
+		Ghost:     Style{FG: "#ff0000", Bold: true},
+		Chaser:    Style{FG: "#ffb8ff", Bold: true},
+		BlueGhost: Style{FG: "#2121ff", Bold: true},
+		HUD:       Style{FG: "#ffffff"},
+	},
+	"high-contrast": {
+		Wall:      Style{FG: "#ffffff"},
+		Dot:       Style{FG: "#ffffff"},
+		Pill:      Style{FG: "#ffff00", Bold: true},
+		Fruit:     Style{FG: "#00ff00", Bold: true},
+		Player:    Style{FG: "#000000", BG: "#ffff00", Bold: true},
+		Ghost:     Style{FG: "#ffffff", BG: "#ff0000", Bold: true},
+		Chaser:    Style{FG: "#ffffff", BG: "#ff00ff", Bold: true},
+		BlueGhost: Style{FG: "#000000", BG: "#00ffff", Bold: true},
+		HUD:       Style{FG: "#ffff00", Bold: true},
+	},
+	"monochrome": {
+		Pill:      Style{Bold: true},
+		Player:    Style{Bold: true},
+		Ghost:     Style{Bold: true},
+		Chaser:    Style{Bold: true},
+		BlueGhost: Style{Reverse: true},
+		HUD:       Style{Bold: true},
+	},
+	// colour-blind-safe uses the Okabe-Ito palette, which stays
+	// distinguishable with every common kind of colour blindness
+	"colour-blind-safe": {
+		Wall:      Style{FG: "#0072b2"},
+		Dot:       Style{FG: "#ffffff"},
+		Pill:      Style{FG: "#f0e442", Bold: true},
+		Fruit:     Style{FG: "#009e73", Bold: true},
+		Player:    Style{FG: "#f0e442", Bold: true},
+		Ghost:     Style{FG: "#d55e00", Bold: true},
+		Chaser:    Style{FG: "#cc79a7", Bold: true},
+		BlueGhost: Style{FG: "#56b4e9", Bold: true},
+		HUD:       Style{FG: "#ffffff"},
+	},
+}
+
+// theme is the theme in use, set by loadConfig
+var theme = themes["classic"]
+
+// findTheme returns the theme called name, or the classic theme if name is
+// empty
+func findTheme(name string) (Theme, error) {
+	if name == "" {
+		name = "classic"
+	}
+	t, ok := themes[name]
+	if !ok {
+		var names []string
+		for n := range themes {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return Theme{}, fmt.Errorf("unknown theme %q, choose one of %s", name, strings.Join(names, ", "))
+	}
+	return t, nil
+}
+
+// spriteStyle returns the style of a sprite in the current theme
+func spriteStyle(s Sprite) Style {
+	switch s := s.(type) {
+	case *Player:
+		return theme.Player
+	case *Chaser:
+		if s.status == GhostStatusBlue {
+			return theme.BlueGhost
+		}
+		return theme.Chaser
+	case *Ghost:
+		if s.status == GhostStatusBlue {
+			return theme.BlueGhost
+		}
+		return theme.Ghost
+	}
+	return Style{}
+}
+
+// ColourLevel is how many colours the terminal can show
+type ColourLevel int
+
+const (
+	colourNone ColourLevel = iota
+	colour16
+	colour256
+	colourTrue
+)
+
+// colourLevel is detected once from the environment
+var colourLevel = detectColourLevel()
+
+// detectColourLevel follows the usual conventions: NO_COLOR turns colours
+// off, COLORTERM announces truecolor and TERM tells the rest
+func detectColourLevel() ColourLevel {
+	if os.Getenv("NO_COLOR") != "" {
+		return colourNone
+	}
+	switch os.Getenv("COLORTERM") {
+	case "truecolor", "24bit":
+		return colourTrue
+	}
+	term := os.Getenv("TERM")
+	switch {
+	case term == "dumb":
+		return colourNone
+	case strings.Contains(term, "256color"):
+		return colour256
+	}
+	return colour16
+}
+
+// code returns the SGR parameters that select c as the foreground or
+// background colour, downgraded to the nearest colour at level l
+func (l ColourLevel) code(c Colour, background bool) string {
+	r, g, b, ok := c.rgb()
+	if !ok || l == colourNone {
+		return ""
+	}
+
+	base := 38
+	if background {
+		base = 48
+	}
+
+	switch l {
+	case colourTrue:
+		return fmt.Sprintf("%d;2;%d;%d;%d", base, r, g, b)
+	case colour256:
+		return fmt.Sprintf("%d;5;%d", base, nearest256(r, g, b))
+	}
+
+	i := nearest16(r, g, b)
+	code := base - 8 + i // 30-37 or 40-47
+	if i >= 8 {
+		code = base + 52 + i - 8 // 90-97 or 100-107
+	}
+	return strconv.Itoa(code)
+}
+
+// ansi16 are the usual xterm values of the 16 basic colours
+var ansi16 = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+func nearest16(r, g, b int) int {
+	best, bestDist := 0, -1
+	for i, c := range ansi16 {
+		d := colourDistance(r, g, b, c[0], c[1], c[2])
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// nearest256 picks the closest colour from the 6x6x6 cube or the grey ramp
+// of the 256 colour palette
+func nearest256(r, g, b int) int {
+	levels := [6]int{0, 95, 135, 175, 215, 255}
+	step := func(v int) int {
+		if v < 48 {
+			return 0
+		}
+		if v < 115 {
+			return 1
+		}
+		return (v - 35) / 40
+	}
+	cr, cg, cb := step(r), step(g), step(b)
+	cube := 16 + 36*cr + 6*cg + cb
+	cubeDist := colourDistance(r, g, b, levels[cr], levels[cg], levels[cb])
+
+	grey := min(max((r+g+b)/3-3, 0)/10, 23)
+	level := 8 + 10*grey
+	if colourDistance(r, g, b, level, level, level) < cubeDist {
+		return 232 + grey
+	}
+	return cube
+}
+
+func colourDistance(r1, g1, b1, r2, g2, b2 int) int {
+	return (r1-r2)*(r1-r2) + (g1-g2)*(g1-g2) + (b1-b2)*(b1-b2)
+}
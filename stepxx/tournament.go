@@ -0,0 +1,287 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// ghostStrategies choose which kind of ghost starts on every ghost tile of
+// a maze, using the same characters as the maze files
+var ghostStrategies = map[string]rune{
+	"maze":   0, // keep the ghosts the maze asks for
+	"random": 'G',
+	"chaser": 'C',
+}
+
+// withGhosts returns a copy of maze where every ghost is of kind ghost
+func withGhosts(maze Maze, ghost rune) Maze {
+	if ghost == 0 {
+		return maze
+	}
+
+	replacer := strings.NewReplacer("G", string(ghost), "C", string(ghost))
+	out := make(Maze, len(maze))
+	for i, line := range maze {
+		out[i] = replacer.Replace(line)
+	}
+	return out
+}
+
+// match is a single game played in a tournament
+type match struct {
+	Player string
+	Ghosts string
+	Maze   string
+	Seed   int64
+}
+
+// matchResult is how a match ended
+type matchResult struct {
+	match
+	Score   int
+	Ticks   int
+	Cleared bool
+}
+
+// Standing summarises every match played by a player bot against a ghost
+// strategy
+type Standing struct {
+	Player       string  `json:"player"`
+	Ghosts       string  `json:"ghosts"`
+	Games        int     `json:"games"`
+	MeanScore    float64 `json:"mean_score"`
+	MedianScore  float64 `json:"median_score"`
+	MeanTicks    float64 `json:"mean_survival_ticks"`
+	MedianTicks  float64 `json:"median_survival_ticks"`
+	ClearRate    float64 `json:"clear_rate"`
+	scores       []int
+	survivalTime []int
+	cleared      int
+}
+
+func runTournament(args []string) error {
+	flags := flag.NewFlagSet("tournament", flag.ExitOnError)
+	players := flags.String("players", strings.Join(controllerNames(), ","), "comma separated player controllers")
+	ghosts := flags.String("ghosts", "maze,random,chaser", "comma separated ghost strategies: maze, random or chaser")
+	mazes := flags.String("mazes", *mazeFile, "comma separated maze files")
+	seeds := flags.Int("seeds", 10, "number of seeds to play each combination with")
+	maxTicks := flags.Int("max-ticks", 5000, "ticks after which a game is stopped")
+	workers := flags.Int("workers", runtime.NumCPU(), "number of games to play in parallel")
+	csvFile := flags.String("csv", "", "write the leaderboard as CSV to this file")
+	jsonFile := flags.String("json", "", "write the leaderboard as JSON to this file")
+	flags.Parse(args)
+
+	err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+
+	playerNames := strings.Split(*players, ",")
+	for _, name := range playerNames {
+		if _, err := NewController(name, 0); err != nil {
+			return err
+		}
+	}
+
+	ghostNames := strings.Split(*ghosts, ",")
+	for _, name := range ghostNames {
+		if _, ok := ghostStrategies[name]; !ok {
+			return fmt.Errorf("unknown ghost strategy %q", name)
+		}
+	}
+
+	mazeFiles := strings.Split(*mazes, ",")
+	loaded := make(map[string]Maze)
+	for _, path := range mazeFiles {
+		loaded[path], err = loadMaze(path)
+		if err != nil {
+			return fmt.Errorf("loading maze %s: %w", path, err)
+		}
+	}
+
+	var matches []match
+	for _, p := range playerNames {
+		for _, g := range ghostNames {
+			for _, m := range mazeFiles {
+				for seed := 1; seed <= *seeds; seed++ {
+					matches = append(matches, match{p, g, m, int64(seed)})
+				}
+			}
+		}
+	}
+
+	jobs := make(chan match)
+	results := make(chan matchResult)
+	var wg sync.WaitGroup
+	for i := 0; i < *workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for m := range jobs {
+				results <- play(m, loaded[m.Maze], *maxTicks)
+			}
+		}()
+	}
+	go func() {
+		for _, m := range matches {
+			jobs <- m
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	standings := make(map[[2]string]*Standing)
+	for r := range results {
+		key := [2]string{r.Player, r.Ghosts}
+		s, ok := standings[key]
+		if !ok {
+			s = &Standing{Player: r.Player, Ghosts: r.Ghosts}
+			standings[key] = s
+		}
+		s.scores = append(s.scores, r.Score)
+		s.survivalTime = append(s.survivalTime, r.Ticks)
+		if r.Cleared {
+			s.cleared++
+		}
+	}
+
+	board := leaderboard(standings)
+	printLeaderboard(board)
+
+	if *csvFile != "" {
+		if err := writeLeaderboardCSV(*csvFile, board); err != nil {
+			return err
+		}
+	}
+	if *jsonFile != "" {
+		if err := writeLeaderboardJSON(*jsonFile, board); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// play runs a match to the end without any rendering or delays
+func play(m match, maze Maze, maxTicks int) matchResult {
+	bot, _ := NewController(m.Player, m.Seed)
+	game := NewGame(withGhosts(maze, ghostStrategies[m.Ghosts]), m.Seed)
+	for !game.Over() && game.stats.Ticks < maxTicks {
+		game.Update(bot.Direction(View{game}))
+	}
+
+	return matchResult{
+		match:   m,
+		Score:   game.player.score,
+		Ticks:   game.stats.Ticks,
+		Cleared: game.numDots == 0,
+	}
+}
+
+// leaderboard computes the summary statistics and ranks the standings by
+// mean score
+func leaderboard(standings map[[2]string]*Standing) []*Standing {
+	var board []*Standing
+	for _, s := range standings {
+		s.Games = len(s.scores)
+		s.MeanScore, s.MedianScore = meanMedian(s.scores)
+		s.MeanTicks, s.MedianTicks = meanMedian(s.survivalTime)
+		s.ClearRate = float64(s.cleared) / float64(s.Games)
+		board = append(board, s)
+	}
+
+	sort.Slice(board, func(i, j int) bool {
+		if board[i].MeanScore != board[j].MeanScore {
+			return board[i].MeanScore > board[j].MeanScore
+		}
+		if board[i].Player != board[j].Player {
+			return board[i].Player < board[j].Player
+		}
+		return board[i].Ghosts < board[j].Ghosts
+	})
+	return board
+}
+
+func meanMedian(values []int) (mean, median float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+
+	sum := 0
+	for _, v := range sorted {
+		sum += v
+	}
+	mean = float64(sum) / float64(len(sorted))
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		median = float64(sorted[mid-1]+sorted[mid]) / 2
+	} else {
+		median = float64(sorted[mid])
+	}
+	return mean, median
+}
+
+var leaderboardHeader = []string{"rank", "player", "ghosts", "games", "mean score", "median score", "mean ticks", "median ticks", "clear rate"}
+
+func leaderboardRow(rank int, s *Standing) []string {
+	return []string{
+		strconv.Itoa(rank),
+		s.Player,
+		s.Ghosts,
+		strconv.Itoa(s.Games),
+		strconv.FormatFloat(s.MeanScore, 'f', 1, 64),
+		strconv.FormatFloat(s.MedianScore, 'f', 1, 64),
+		strconv.FormatFloat(s.MeanTicks, 'f', 1, 64),
+		strconv.FormatFloat(s.MedianTicks, 'f', 1, 64),
+		strconv.FormatFloat(s.ClearRate, 'f', 2, 64),
+	}
+}
+
+func printLeaderboard(board []*Standing) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, strings.Join(leaderboardHeader, "\t")+"\t")
+	for i, s := range board {
+		fmt.Fprintln(w, strings.Join(leaderboardRow(i+1, s), "\t")+"\t")
+	}
+	w.Flush()
+}
+
+func writeLeaderboardCSV(path string, board []*Standing) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write(leaderboardHeader)
+	for i, s := range board {
+		w.Write(leaderboardRow(i+1, s))
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func writeLeaderboardJSON(path string, board []*Standing) error {
+	data, err := json.MarshalIndent(board, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
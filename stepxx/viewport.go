@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// hudLines are the rows below the maze: a blank line, the score and the
+// notices
+const hudLines = 3
+
+// The smallest part of the maze, in tiles, worth playing on. Mazes smaller
+// than that need to be shown whole.
+const (
+	minViewRows = 8
+	minViewCols = 16
+)
+
+// terminal size in characters, zero while unknown. It is written by the
+// resize handler and read when drawing each frame.
+var termRows, termCols atomic.Int32
+
+// updateTerminalSize asks stty for the size of the terminal. The size is
+// left as it was if stty fails, e.g. when stdin is not a terminal.
+func updateTerminalSize() {
+	cmd := exec.Command("stty", "size")
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	if err != nil {
+		return
+	}
+
+	fields := strings.Fields(string(out))
+	if len(fields) != 2 {
+		return
+	}
+	rows, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return
+	}
+	cols, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return
+	}
+	termRows.Store(int32(rows))
+	termCols.Store(int32(cols))
+}
+
+// viewport is the part of the maze shown on the terminal, in tiles
+type viewport struct {
+	top, left  int
+	rows, cols int
+
+	// minRows and minCols are the smallest size worth playing on for this
+	// maze
+	minRows, minCols int
+}
+
+// newViewport fits as much of maze as the terminal can show, centred on
+// focus but without scrolling past the edges of the maze. The whole maze
+// is shown if the terminal size is unknown.
+func newViewport(maze Maze, focus Point) viewport {
+	width := maze.Width()
+	v := viewport{
+		rows:    len(maze),
+		cols:    width,
+		minRows: min(minViewRows, len(maze)),
+		minCols: min(minViewCols, width),
+	}
+
+	if rows := int(termRows.Load()); rows > 0 {
+		v.rows = max(min(v.rows, rows-hudLines), 0)
+	}
+	if cols := int(termCols.Load()); cols > 0 {
+		if cfg.UseEmoji {
+			cols = cols / 2
+		}
+		v.cols = min(v.cols, cols)
+	}
+
+	v.top = max(min(focus.row-v.rows/2, len(maze)-v.rows), 0)
+	v.left = max(min(focus.col-v.cols/2, width-v.cols), 0)
+	return v
+}
+
+// tooSmall reports whether the terminal can't fit enough of the maze to play
+func (v viewport) tooSmall() bool {
+	return v.rows < v.minRows || v.cols < v.minCols
+}
+
+// contains reports whether the maze tile p is on screen
+func (v viewport) contains(p Point) bool {
+	return p.row >= v.top && p.row < v.top+v.rows && p.col >= v.left && p.col < v.left+v.cols
+}
+
+// moveCursor moves to the maze tile p, which must be on screen
+func (v viewport) moveCursor(w io.Writer, p Point) {
+	moveCursor(w, p.row-v.top, p.col-v.left)
+}
+
+// hudRow returns the terminal row of the score line
+func (v viewport) hudRow() int {
+	return v.rows + 1
+}
+
+// width returns how many terminal columns the viewport takes up
+func (v viewport) width() int {
+	if cfg.UseEmoji {
+		return v.cols * 2
+	}
+	return v.cols
+}
+
+// printTooSmall replaces the maze with a request for a terminal big enough
+// for v
+func printTooSmall(w io.Writer, v viewport) {
+	cols := v.minCols
+	if cfg.UseEmoji {
+		cols = cols * 2
+	}
+	fmt.Fprintln(w, "Terminal too small.")
+	fmt.Fprintf(w, "Please resize it to at least %dx%d.\n", cols, v.minRows+hudLines)
+}
@@ -0,0 +1,149 @@
+package main
+
+// Wall styles
+const (
+	wallSolid = "solid" // every wall tile is cfg.Wall on a blue background
+	wallBox   = "box"   // walls are outlined with box-drawing characters
+)
+
+// Neighbour bits of a wall tile, set when the outline continues that way
+const (
+	wallUp = 1 << iota
+	wallRight
+	wallDown
+	wallLeft
+)
+
+// wallGlyphs map the neighbour bits of a wall tile to the box-drawing
+// character for it, with single lines for the inner walls and double lines
+// for the outer border
+var (
+	singleWallGlyphs = [16]string{"■", "│", "─", "└", "│", "│", "┌", "├", "─", "┘", "─", "┴", "┐", "┤", "┬", "┼"}
+	doubleWallGlyphs = [16]string{"■", "║", "═", "╚", "║", "║", "╔", "╠", "═", "╝", "═", "╩", "╗", "╣", "╦", "╬"}
+)
+
+// WallTiles are the box-drawing glyphs for every wall of a maze, worked out
+// once since walls never move
+type WallTiles [][]string
+
+// NewWallTiles picks a glyph for every wall tile of maze. Walls that are
+// thicker than one tile are drawn as their outline, and walls connected to
+// the edge of the maze get double lines, like the border of the arcade
+// maze. In emoji mode each glyph is two columns wide, extending the line to
+// the right when the wall continues that way.
+func NewWallTiles(maze Maze) WallTiles {
+	outer := outerWalls(maze)
+
+	tiles := make(WallTiles, len(maze))
+	for row, line := range maze {
+		tiles[row] = make([]string, len(line))
+		for col := range line {
+			if !isWall(maze, row, col) {
+				continue
+			}
+
+			glyphs := &singleWallGlyphs
+			if outer[Point{row, col}] {
+				glyphs = &doubleWallGlyphs
+			}
+
+			bits := wallBits(maze, row, col)
+			glyph := glyphs[bits]
+			if bits == 0 && isSolid(maze, row, col) {
+				glyph = " "
+			}
+			if cfg.UseEmoji {
+				if bits&wallRight != 0 {
+					glyph += glyphs[wallLeft|wallRight]
+				} else {
+					glyph += " "
+				}
+			}
+			tiles[row][col] = glyph
+		}
+	}
+	return tiles
+}
+
+// isWall reports whether there is a wall at row and col. Anything outside
+// the maze counts as open space, so the border gets an outline too.
+func isWall(maze Maze, row, col int) bool {
+	if row < 0 || row >= len(maze) || col < 0 || col >= len(maze[row]) {
+		return false
+	}
+	return maze[row][col] == '#'
+}
+
+// wallBits returns which neighbours the outline of the wall at row and col
+// continues to. Two neighbouring walls are only joined when the edge
+// between them has open space on one side, so a thick block of walls is
+// drawn as a rectangle instead of a grid.
+func wallBits(maze Maze, row, col int) int {
+	bits := 0
+	if isWall(maze, row-1, col) && onOutline(maze, row-1, col, row, col) {
+		bits |= wallUp
+	}
+	if isWall(maze, row, col+1) && onOutline(maze, row, col, row, col+1) {
+		bits |= wallRight
+	}
+	if isWall(maze, row+1, col) && onOutline(maze, row, col, row+1, col) {
+		bits |= wallDown
+	}
+	if isWall(maze, row, col-1) && onOutline(maze, row, col-1, row, col) {
+		bits |= wallLeft
+	}
+	return bits
+}
+
+// onOutline reports whether the edge between the neighbouring walls a and b
+// (b below or to the right of a) has open space on at least one side
+func onOutline(maze Maze, aRow, aCol, bRow, bCol int) bool {
+	if aRow == bRow {
+		// horizontal edge: look above and below
+		return !(isWall(maze, aRow-1, aCol) && isWall(maze, bRow-1, bCol)) ||
+			!(isWall(maze, aRow+1, aCol) && isWall(maze, bRow+1, bCol))
+	}
+	// vertical edge: look left and right
+	return !(isWall(maze, aRow, aCol-1) && isWall(maze, bRow, bCol-1)) ||
+		!(isWall(maze, aRow, aCol+1) && isWall(maze, bRow, bCol+1))
+}
+
+// isSolid reports whether the wall at row and col is surrounded by walls,
+// diagonals included, so it is inside a thick block
+func isSolid(maze Maze, row, col int) bool {
+	for dr := -1; dr <= 1; dr++ {
+		for dc := -1; dc <= 1; dc++ {
+			if !isWall(maze, row+dr, col+dc) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// outerWalls returns the walls connected to the edge of the maze
+func outerWalls(maze Maze) map[Point]bool {
+	outer := make(map[Point]bool)
+	var queue []Point
+	for row, line := range maze {
+		for col := range line {
+			edge := row == 0 || row == len(maze)-1 || col == 0 || col == len(line)-1
+			if edge && isWall(maze, row, col) {
+				outer[Point{row, col}] = true
+				queue = append(queue, Point{row, col})
+			}
+		}
+	}
+
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, n := range []Point{{p.row - 1, p.col}, {p.row + 1, p.col}, {p.row, p.col - 1}, {p.row, p.col + 1}} {
+			if isWall(maze, n.row, n.col) && !outer[n] {
+				outer[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	return outer
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// webAssets is the browser client. It is embedded so the web front-end
+// works offline and from any directory.
+//
+//go:embed web
+var webAssets embed.FS
+
+// webFrame is the snapshot of a game sent to browsers on every tick
+type webFrame struct {
+	Maze    []string    `json:"maze"`
+	Sprites []webSprite `json:"sprites"`
+	Score   int         `json:"score"`
+	Lives   int         `json:"lives"`
+	Over    bool        `json:"over"`
+}
+
+type webSprite struct {
+	Kind string `json:"kind"`
+	Row  int    `json:"row"`
+	Col  int    `json:"col"`
+	Blue bool   `json:"blue,omitempty"`
+}
+
+func newWebFrame(g *Game) webFrame {
+	frame := webFrame{
+		Maze:  append([]string(nil), g.maze...),
+		Score: g.player.score,
+		Lives: g.player.lives,
+		Over:  g.Over(),
+	}
+
+	if pos, ok := g.FruitPos(); ok {
+		frame.Sprites = append(frame.Sprites, webSprite{Kind: "fruit", Row: pos.row, Col: pos.col})
+	}
+
+	for _, s := range g.sprites {
+		var sprite webSprite
+		switch s := s.(type) {
+		case *Player:
+			sprite.Kind = "player"
+		case *Chaser:
+			sprite.Kind = "chaser"
+			sprite.Blue = s.status == GhostStatusBlue
+		case *Ghost:
+			sprite.Kind = "ghost"
+			sprite.Blue = s.status == GhostStatusBlue
+		}
+		sprite.Row, sprite.Col = s.Pos()
+		frame.Sprites = append(frame.Sprites, sprite)
+	}
+
+	return frame
+}
+
+// webServer runs a single shared game and streams it to every connected
+// browser with server-sent events. Any browser can steer the player by
+// POSTing a direction to /input.
+type webServer struct {
+	maze  Maze
+	input chan string
+
+	mu   sync.Mutex
+	subs map[chan []byte]bool
+	last []byte
+}
+
+func runWeb(args []string) error {
+	flags := flag.NewFlagSet("web", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "address to serve the browser front-end on")
+	flags.Parse(args)
+
+	err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+
+	maze, err := loadMaze(*mazeFile)
+	if err != nil {
+		return fmt.Errorf("loading maze: %w", err)
+	}
+
+	static, err := fs.Sub(webAssets, "web")
+	if err != nil {
+		return err
+	}
+
+	s := &webServer{
+		maze:  maze,
+		input: make(chan string, 1),
+		subs:  make(map[chan []byte]bool),
+	}
+	go s.run()
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /", http.FileServerFS(static))
+	mux.HandleFunc("GET /events", s.events)
+	mux.HandleFunc("POST /input", s.postInput)
+
+	log.Println("Serving pacgo on", *addr)
+	return http.ListenAndServe(*addr, mux)
+}
+
+// run plays games back to back for as long as the server is up
+func (s *webServer) run() {
+	for {
+		game := NewGame(withDifficultyGhosts(s.maze), time.Now().UnixNano())
+		for {
+			var inp string
+			select {
+			case inp = <-s.input:
+			default:
+			}
+			game.Update(inp)
+
+			s.broadcast(newWebFrame(game))
+			if game.Over() {
+				break
+			}
+
+			time.Sleep(time.Second / time.Duration(cfg.FrameRate))
+		}
+
+		// leave the final frame up for a moment before starting over
+		time.Sleep(3 * time.Second)
+	}
+}
+
+func (s *webServer) broadcast(frame webFrame) {
+	data, err := json.Marshal(frame)
+	if err != nil {
+		log.Println("Error encoding frame:", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last = data
+	for ch := range s.subs {
+		// browsers that fall behind skip frames rather than slowing the game
+		select {
+		case ch <- data:
+		default:
+		}
+	}
+}
+
+func (s *webServer) subscribe() chan []byte {
+	ch := make(chan []byte, 1)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subs[ch] = true
+	if s.last != nil {
+		ch <- s.last
+	}
+	return ch
+}
+
+func (s *webServer) unsubscribe(ch chan []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subs, ch)
+}
+
+func (s *webServer) events(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	ch := s.subscribe()
+	defer s.unsubscribe(ch)
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case data := <-ch:
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *webServer) postInput(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 16))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	dir := strings.TrimSpace(string(body))
+	switch dir {
+	case "UP", "DOWN", "LEFT", "RIGHT":
+	default:
+		http.Error(w, "invalid direction: "+dir, http.StatusBadRequest)
+		return
+	}
+
+	// only the most recent key press matters
+	select {
+	case <-s.input:
+	default:
+	}
+	select {
+	case s.input <- dir:
+	default:
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,82 @@
+// Pac Go browser client: draws frames streamed from /events and sends
+// key presses to /input.
+(function () {
+  "use strict";
+
+  var TILE = 20;
+  var canvas = document.getElementById("maze");
+  var ctx = canvas.getContext("2d");
+  var status = document.getElementById("status");
+
+  var colours = {
+    wall: "#2121de",
+    dot: "#ffb8ae",
+    player: "#ffff00",
+    ghost: "#ff0000",
+    chaser: "#ffb8ff",
+    fruit: "#ff2020",
+    blue: "#2121ff"
+  };
+
+  function circle(row, col, radius, colour) {
+    ctx.fillStyle = colour;
+    ctx.beginPath();
+    ctx.arc(col * TILE + TILE / 2, row * TILE + TILE / 2, radius, 0, 2 * Math.PI);
+    ctx.fill();
+  }
+
+  function draw(frame) {
+    var width = 0;
+    frame.maze.forEach(function (line) { width = Math.max(width, line.length); });
+    canvas.width = width * TILE;
+    canvas.height = frame.maze.length * TILE;
+
+    ctx.fillStyle = "#000";
+    ctx.fillRect(0, 0, canvas.width, canvas.height);
+
+    frame.maze.forEach(function (line, row) {
+      for (var col = 0; col < line.length; col++) {
+        switch (line[col]) {
+        case "#":
+          ctx.fillStyle = colours.wall;
+          ctx.fillRect(col * TILE, row * TILE, TILE, TILE);
+          break;
+        case ".":
+          circle(row, col, TILE / 10, colours.dot);
+          break;
+        case "X":
+          circle(row, col, TILE / 3, colours.dot);
+          break;
+        }
+      }
+    });
+
+    frame.sprites.forEach(function (s) {
+      var colour = s.blue ? colours.blue : colours[s.kind];
+      circle(s.row, s.col, TILE / 2 - 1, colour || colours.ghost);
+    });
+
+    status.textContent = "Score: " + frame.score + "  Lives: " + frame.lives +
+      (frame.over ? "  GAME OVER" : "");
+  }
+
+  var events = new EventSource("events");
+  events.onmessage = function (e) { draw(JSON.parse(e.data)); };
+  events.onerror = function () { status.textContent = "Disconnected, retrying..."; };
+
+  var keys = {
+    ArrowUp: "UP",
+    ArrowDown: "DOWN",
+    ArrowLeft: "LEFT",
+    ArrowRight: "RIGHT"
+  };
+
+  document.addEventListener("keydown", function (e) {
+    var dir = keys[e.key];
+    if (!dir) {
+      return;
+    }
+    e.preventDefault();
+    fetch("input", { method: "POST", body: dir });
+  });
+})();